@@ -0,0 +1,173 @@
+package impi
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// verifyThirdPartyAllowlist verifies that the third party imports of the file are allowed by the
+// allowlist of its directory
+func (v *verifier) verifyThirdPartyAllowlist(importInfoGroups []importInfoGroup) (ruleViolations, error) {
+	thirdPartyAllowlist, err := v.getThirdPartyAllowlist()
+	if err != nil || thirdPartyAllowlist == nil {
+		return nil, err
+	}
+
+	allowedPrefixes, err := v.getAllowedPrefixes(thirdPartyAllowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	var module *goModule
+	if thirdPartyAllowlist.GoMod {
+		module, err = findGoModule(filepath.Dir(v.filePath))
+		if err != nil {
+			return nil, err
+		}
+
+		if module == nil {
+			return nil, fmt.Errorf("Third party allowlist of %s requires a go.mod", thirdPartyAllowlist.Dir)
+		}
+
+		allowedPrefixes = append(allowedPrefixes, module.requires...)
+	}
+
+	var violations ruleViolations
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			if !v.isThirdPartyImport(importInfo, module) || matchImportPathPrefixes(allowedPrefixes, importInfo.path) {
+				continue
+			}
+
+			message := fmt.Sprintf("Third party import %s is not allowed in %s", importInfo.path, thirdPartyAllowlist.Dir)
+			if module != nil {
+				message += fmt.Sprintf(" (not required by %s)", filepath.Join(module.rootDir, "go.mod"))
+			}
+
+			violations = append(violations, &ruleViolation{
+				rule:    ruleThirdPartyImport,
+				lineNum: importInfo.lineNum,
+				message: message,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// getThirdPartyAllowlist returns the allowlist of the deepest directory containing the file, or nil if
+// the file isn't subject to any allowlist
+func (v *verifier) getThirdPartyAllowlist() (*ThirdPartyAllowlist, error) {
+	var thirdPartyAllowlist *ThirdPartyAllowlist
+	var thirdPartyAllowlistDirPath string
+
+	absFilePath, err := filepath.Abs(v.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for thirdPartyAllowlistIndex := range v.verifyOptions.ThirdPartyAllowlists {
+		candidateAllowlist := &v.verifyOptions.ThirdPartyAllowlists[thirdPartyAllowlistIndex]
+
+		absDirPath, err := filepath.Abs(candidateAllowlist.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isPathUnderDir(absFilePath, absDirPath) || len(absDirPath) < len(thirdPartyAllowlistDirPath) {
+			continue
+		}
+
+		thirdPartyAllowlist = candidateAllowlist
+		thirdPartyAllowlistDirPath = absDirPath
+	}
+
+	return thirdPartyAllowlist, nil
+}
+
+// getAllowedPrefixes returns the prefixes the allowlist specifies, either directly or in its file
+func (v *verifier) getAllowedPrefixes(thirdPartyAllowlist *ThirdPartyAllowlist) ([]string, error) {
+	allowedPrefixes := append([]string{}, thirdPartyAllowlist.Prefixes...)
+
+	if thirdPartyAllowlist.File == "" {
+		return allowedPrefixes, nil
+	}
+
+	// files are read once per verifier
+	filePrefixes, found := v.allowlistFilePrefixes[thirdPartyAllowlist.File]
+	if !found {
+		var err error
+
+		filePrefixes, err = readAllowlistFile(thirdPartyAllowlist.File)
+		if err != nil {
+			return nil, err
+		}
+
+		v.allowlistFilePrefixes[thirdPartyAllowlist.File] = filePrefixes
+	}
+
+	return append(allowedPrefixes, filePrefixes...), nil
+}
+
+// isThirdPartyImport returns whether the import is a third party import. If there's no local prefix to tell
+// local and third party imports apart, imports that are not part of the module are third party
+func (v *verifier) isThirdPartyImport(importInfo *importInfo, module *goModule) bool {
+	switch importInfo.classifiedType {
+	case importTypeThirdParty:
+		return true
+	case importTypeLocalOrThirdParty:
+		return module == nil || !matchImportPathPrefixes([]string{module.path}, importInfo.path)
+	default:
+		return false
+	}
+}
+
+func readAllowlistFile(filePath string) ([]string, error) {
+	allowlistFile, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer allowlistFile.Close()
+
+	var prefixes []string
+	s := bufio.NewScanner(allowlistFile)
+
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		prefixes = append(prefixes, line)
+	}
+
+	return prefixes, s.Err()
+}
+
+// matchImportPathPrefixes returns whether the import path is any of the prefixes or under any of them
+func matchImportPathPrefixes(prefixes []string, importPath string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+
+		if importPath == prefix || strings.HasPrefix(importPath, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPathUnderDir(path string, dirPath string) bool {
+	relPath, err := filepath.Rel(dirPath, path)
+	if err != nil {
+		return false
+	}
+
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
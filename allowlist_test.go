@@ -0,0 +1,115 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ThirdPartyAllowlistTestSuite struct {
+	VerifierTestSuite
+	tempDir string
+}
+
+func (s *ThirdPartyAllowlistTestSuite) SetupSuite() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-allowlist")
+	s.Require().NoError(err)
+
+	goModContents := `module github.com/pavius/impi
+
+require github.com/kisielk/gotool v1.0.0
+
+require (
+	github.com/stretchr/testify v1.2.2 // indirect
+)
+`
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "go.mod"), []byte(goModContents), 0644))
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "allowlist"), []byte("# approved\ngolang.org/x/\n"), 0644))
+
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.ThirdPartyAllowlists = []ThirdPartyAllowlist{
+		{
+			Dir:   s.tempDir,
+			File:  path.Join(s.tempDir, "allowlist"),
+			GoMod: true,
+		},
+		{
+			Dir:      path.Join(s.tempDir, "legacy"),
+			Prefixes: []string{"github.com/pkg/errors"},
+		},
+	}
+}
+
+func (s *ThirdPartyAllowlistTestSuite) TearDownSuite() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *ThirdPartyAllowlistTestSuite) TestThirdPartyAllowlist() {
+	s.filePath = path.Join(s.tempDir, "pkg", "a", "a.go")
+
+	s.verifyTestCases([]verificationTestCase{
+		{
+			name: "Allowed imports (valid)",
+			contents: `package fixtures
+import (
+    "fmt"
+
+    "github.com/pavius/impi/pkg/b"
+
+    "github.com/kisielk/gotool"
+    "github.com/stretchr/testify/suite"
+    "golang.org/x/tools/go/packages"
+)
+`,
+		},
+		{
+			name: "Not allowed imports (invalid)",
+			contents: `package fixtures
+import (
+    "github.com/pkg/errors"
+    "github.com/stretchr/testify2"
+)
+`,
+			expectedErrorStrings: []string{
+				"Third party import github.com/pkg/errors is not allowed in " + s.tempDir,
+				"Third party import github.com/stretchr/testify2 is not allowed in " + s.tempDir,
+				"(not required by " + path.Join(s.tempDir, "go.mod") + ")",
+			},
+		},
+	})
+
+	// the deepest allowlist applies
+	s.filePath = path.Join(s.tempDir, "legacy", "a.go")
+
+	s.verifyTestCases([]verificationTestCase{
+		{
+			name: "Allowed imports in subtree (valid)",
+			contents: `package fixtures
+import (
+    "github.com/pkg/errors"
+)
+`,
+		},
+		{
+			name: "Not allowed imports in subtree (invalid)",
+			contents: `package fixtures
+import (
+    "github.com/kisielk/gotool"
+)
+`,
+			expectedErrorStrings: []string{
+				"Third party import github.com/kisielk/gotool is not allowed in " + path.Join(s.tempDir, "legacy"),
+			},
+		},
+	})
+}
+
+func TestThirdPartyAllowlistTestSuite(t *testing.T) {
+	suite.Run(t, new(ThirdPartyAllowlistTestSuite))
+}
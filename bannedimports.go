@@ -0,0 +1,71 @@
+package impi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// verifyBannedImports verifies that none of the imports are banned
+func (v *verifier) verifyBannedImports(importInfoGroups []importInfoGroup) ruleViolations {
+	var violations ruleViolations
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			bannedImport := findBannedImport(v.verifyOptions.BannedImports, importInfo.path)
+			if bannedImport == nil {
+				continue
+			}
+
+			message := fmt.Sprintf("Import of %s is banned", importInfo.path)
+			if bannedImport.Message != "" {
+				message += ": " + bannedImport.Message
+			}
+
+			violations = append(violations, &ruleViolation{
+				rule:    ruleBannedImport,
+				lineNum: importInfo.lineNum,
+				message: message,
+			})
+		}
+	}
+
+	return violations
+}
+
+// findBannedImport returns the banned import matching the import path, or nil if the path isn't banned
+func findBannedImport(bannedImports []BannedImport, importPath string) *BannedImport {
+	for bannedImportIndex := range bannedImports {
+		bannedImport := &bannedImports[bannedImportIndex]
+
+		if bannedImport.matches(importPath) {
+			return bannedImport
+		}
+	}
+
+	return nil
+}
+
+func (bi *BannedImport) matches(importPath string) bool {
+	if !strings.HasSuffix(bi.Path, "/...") {
+		return importPath == bi.Path
+	}
+
+	bannedPathPrefix := strings.TrimSuffix(bi.Path, "/...")
+
+	return importPath == bannedPathPrefix || strings.HasPrefix(importPath, bannedPathPrefix+"/")
+}
+
+// getReplacement returns the import path replacing the (banned) import path, or an empty string if
+// there is no drop-in replacement
+func (bi *BannedImport) getReplacement(importPath string) string {
+	if bi.Replacement == "" || !strings.HasSuffix(bi.Path, "/...") {
+		return bi.Replacement
+	}
+
+	// replacing a prefix requires the replacement to be a prefix as well
+	if !strings.HasSuffix(bi.Replacement, "/...") {
+		return ""
+	}
+
+	return strings.TrimSuffix(bi.Replacement, "/...") + strings.TrimPrefix(importPath, strings.TrimSuffix(bi.Path, "/..."))
+}
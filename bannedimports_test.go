@@ -0,0 +1,67 @@
+package impi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BannedImportsTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *BannedImportsTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.BannedImports = []BannedImport{
+		{Path: "io/ioutil", Message: "use os or io instead"},
+		{Path: "github.com/pkg/errors", Replacement: "errors"},
+		{Path: "github.com/old/lib/...", Replacement: "github.com/new/lib/..."},
+	}
+}
+
+func (s *BannedImportsTestSuite) TestBannedImports() {
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "No banned imports (valid)",
+			contents: `package fixtures
+import (
+    "errors"
+    "io"
+
+    "github.com/new/lib"
+    "github.com/old/library"
+)
+`,
+		},
+		{
+			name: "Banned imports (invalid)",
+			contents: `package fixtures
+import (
+    "io/ioutil"
+
+    "github.com/old/lib/sub"
+    "github.com/pkg/errors"
+)
+`,
+			expectedErrorStrings: []string{
+				"Import of io/ioutil is banned: use os or io instead",
+				"Import of github.com/old/lib/sub is banned",
+				"Import of github.com/pkg/errors is banned",
+			},
+		},
+	}
+	s.verifyTestCases(verificationTestCases)
+}
+
+func (s *BannedImportsTestSuite) TestGetReplacement() {
+	s.Require().Equal("", findBannedImport(s.options.BannedImports, "io/ioutil").getReplacement("io/ioutil"))
+	s.Require().Equal("errors", findBannedImport(s.options.BannedImports, "github.com/pkg/errors").getReplacement("github.com/pkg/errors"))
+	s.Require().Equal("github.com/new/lib/sub", findBannedImport(s.options.BannedImports, "github.com/old/lib/sub").getReplacement("github.com/old/lib/sub"))
+	s.Require().Nil(findBannedImport(s.options.BannedImports, "github.com/old/library"))
+}
+
+func TestBannedImportsTestSuite(t *testing.T) {
+	suite.Run(t, new(BannedImportsTestSuite))
+}
@@ -0,0 +1,148 @@
+package impi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// verifyImportBoundaries verifies that the package of the file only imports local packages it may
+func (v *verifier) verifyImportBoundaries(importInfoGroups []importInfoGroup) (ruleViolations, error) {
+	if len(v.verifyOptions.ImportBoundaries) == 0 && !v.verifyOptions.EnforceInternalImports {
+		return nil, nil
+	}
+
+	module, err := v.getLocalModule(filepath.Dir(v.filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	importerPath, err := module.getPackagePath(filepath.Dir(v.filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	importerRelPath := getModuleRelPath(module, importerPath)
+
+	var violations ruleViolations
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			if v.verifyOptions.EnforceInternalImports && !isInternalImportAllowed(importerPath, importInfo.path) {
+				violations = append(violations, &ruleViolation{
+					rule:    ruleInternalImport,
+					lineNum: importInfo.lineNum,
+					message: fmt.Sprintf("Package %s may not import internal package %s", importerPath, importInfo.path),
+				})
+			}
+
+			// boundaries only apply to packages of the module
+			if importInfo.path != module.path && !strings.HasPrefix(importInfo.path, module.path+"/") {
+				continue
+			}
+
+			importRelPath := getModuleRelPath(module, importInfo.path)
+
+			for _, importBoundary := range v.verifyOptions.ImportBoundaries {
+				if importBoundary.allows(importerRelPath, importRelPath) {
+					continue
+				}
+
+				message := fmt.Sprintf("Package %s may not import %s", importerPath, importInfo.path)
+				if importBoundary.Name != "" {
+					message += fmt.Sprintf(" (%s)", importBoundary.Name)
+				}
+
+				violations = append(violations, &ruleViolation{
+					rule:    ruleImportBoundary,
+					lineNum: importInfo.lineNum,
+					message: message,
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// getLocalModule returns the module of the directory. If the directory isn't part of a module, the
+// working directory is assumed to be the root of a module whose path is the local prefix
+func (v *verifier) getLocalModule(dirPath string) (*goModule, error) {
+	module, err := findGoModule(dirPath)
+	if err != nil || module != nil {
+		return module, err
+	}
+
+	if v.verifyOptions.LocalPrefix == "" {
+		return nil, errors.New("Import boundaries require either a go.mod or a local prefix")
+	}
+
+	workingDirPath, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	return &goModule{
+		rootDir: workingDirPath,
+		path:    strings.TrimSuffix(v.verifyOptions.LocalPrefix, "/"),
+	}, nil
+}
+
+func (ib *ImportBoundary) allows(importerRelPath string, importRelPath string) bool {
+	if !matchPackagePatterns(ib.Packages, importerRelPath) {
+		return true
+	}
+
+	return !matchPackagePatterns(ib.Deny, importRelPath) || matchPackagePatterns(ib.Allow, importRelPath)
+}
+
+// matchPackagePatterns returns whether a package path, relative to the module, matches any of the patterns.
+// Patterns ending with "/..." match the package and all packages under it, "..." matches all packages
+// and "." matches the root package of the module
+func matchPackagePatterns(packagePatterns []string, relPath string) bool {
+	for _, packagePattern := range packagePatterns {
+		switch {
+		case packagePattern == "...":
+			return true
+		case packagePattern == ".":
+			if relPath == "" {
+				return true
+			}
+		case strings.HasSuffix(packagePattern, "/..."):
+			packagePatternPrefix := strings.TrimSuffix(packagePattern, "/...")
+
+			if relPath == packagePatternPrefix || strings.HasPrefix(relPath, packagePatternPrefix+"/") {
+				return true
+			}
+		case relPath == packagePattern:
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInternalImportAllowed returns whether an internal package may be imported, following Go's rules: a package
+// under an "internal" element may only be imported by packages rooted at the parent of that element
+func isInternalImportAllowed(importerPath string, importPath string) bool {
+	importPathElements := strings.Split(importPath, "/")
+
+	for importPathElementIndex := len(importPathElements) - 1; importPathElementIndex > 0; importPathElementIndex-- {
+		if importPathElements[importPathElementIndex] != "internal" {
+			continue
+		}
+
+		internalRootPath := strings.Join(importPathElements[:importPathElementIndex], "/")
+
+		return importerPath == internalRootPath || strings.HasPrefix(importerPath, internalRootPath+"/")
+	}
+
+	return true
+}
+
+// getModuleRelPath returns the path of a package relative to the module, which is empty for its root package
+func getModuleRelPath(module *goModule, packagePath string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(packagePath, module.path), "/")
+}
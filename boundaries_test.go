@@ -0,0 +1,131 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ImportBoundariesTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *ImportBoundariesTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi/"
+	s.options.EnforceInternalImports = true
+	s.options.ImportBoundaries = []ImportBoundary{
+		{
+			Name:     "api-no-storage",
+			Packages: []string{"pkg/api/..."},
+			Deny:     []string{"pkg/storage/..."},
+			Allow:    []string{"pkg/storage/types"},
+		},
+	}
+}
+
+func (s *ImportBoundariesTestSuite) TestImportBoundaries() {
+	s.filePath = "pkg/api/v1/handler.go"
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Allowed imports (valid)",
+			contents: `package fixtures
+import (
+    "github.com/pavius/impi/pkg/api/internal/auth"
+    "github.com/pavius/impi/pkg/common"
+    "github.com/pavius/impi/pkg/storage/types"
+)
+`,
+		},
+		{
+			name: "Denied imports (invalid)",
+			contents: `package fixtures
+import (
+    "github.com/pavius/impi/pkg/storage"
+    "github.com/pavius/impi/pkg/storage/sql"
+)
+`,
+			expectedErrorStrings: []string{
+				"Package github.com/pavius/impi/pkg/api/v1 may not import github.com/pavius/impi/pkg/storage (api-no-storage)",
+				"Package github.com/pavius/impi/pkg/api/v1 may not import github.com/pavius/impi/pkg/storage/sql (api-no-storage)",
+			},
+		},
+		{
+			name: "Internal imports (invalid)",
+			contents: `package fixtures
+import (
+    "github.com/pavius/impi/pkg/storage/internal/sql"
+)
+`,
+			expectedErrorStrings: []string{
+				"Package github.com/pavius/impi/pkg/api/v1 may not import internal package github.com/pavius/impi/pkg/storage/internal/sql",
+			},
+		},
+	}
+	s.verifyTestCases(verificationTestCases)
+
+	// the boundary doesn't apply to other packages
+	s.filePath = "cmd/impi/main.go"
+
+	s.verifyTestCases([]verificationTestCase{
+		{
+			name: "Unbounded package (valid)",
+			contents: `package fixtures
+import (
+    "github.com/pavius/impi/pkg/storage"
+)
+`,
+		},
+	})
+}
+
+func TestImportBoundariesTestSuite(t *testing.T) {
+	suite.Run(t, new(ImportBoundariesTestSuite))
+}
+
+type GoModTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (s *GoModTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-gomod")
+	s.Require().NoError(err)
+}
+
+func (s *GoModTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *GoModTestSuite) TestFindGoModule() {
+	goModContents := `// the module
+module "github.com/pavius/impi" // with a comment
+
+go 1.12
+`
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "go.mod"), []byte(goModContents), 0644))
+	s.Require().NoError(os.MkdirAll(path.Join(s.tempDir, "pkg", "a"), 0755))
+
+	module, err := findGoModule(path.Join(s.tempDir, "pkg", "a"))
+	s.Require().NoError(err)
+	s.Require().NotNil(module)
+	s.Require().Equal("github.com/pavius/impi", module.path)
+
+	packagePath, err := module.getPackagePath(path.Join(s.tempDir, "pkg", "a"))
+	s.Require().NoError(err)
+	s.Require().Equal("github.com/pavius/impi/pkg/a", packagePath)
+
+	packagePath, err = module.getPackagePath(s.tempDir)
+	s.Require().NoError(err)
+	s.Require().Equal("github.com/pavius/impi", packagePath)
+}
+
+func TestGoModTestSuite(t *testing.T) {
+	suite.Run(t, new(GoModTestSuite))
+}
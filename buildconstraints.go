@@ -0,0 +1,163 @@
+package impi
+
+import (
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// known values of GOOS and GOARCH, as go/build knows them. only these are matched in file names
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+	"plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true, "arm64be": true,
+	"loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+	"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true, "riscv64": true,
+	"s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+// matchFile returns whether a file would be built under the constraints, considering both its name
+// (e.g. foo_linux_amd64.go) and its //go:build (or // +build) lines
+func (bc *BuildConstraints) matchFile(filePath string, source []byte) (bool, error) {
+	if !bc.matchFileName(filepath.Base(filePath)) {
+		return false, nil
+	}
+
+	buildExpr, err := getBuildExpr(source)
+	if err != nil {
+		return false, err
+	}
+
+	// files without constraints are always built
+	if buildExpr == nil {
+		return true, nil
+	}
+
+	return buildExpr.Eval(bc.matchTag), nil
+}
+
+func (bc *BuildConstraints) matchFileName(fileName string) bool {
+	fileName = strings.TrimSuffix(strings.TrimSuffix(fileName, ".go"), "_test")
+
+	// the first element of the name is never a constraint (e.g. linux.go)
+	underscoreIndex := strings.Index(fileName, "_")
+	if underscoreIndex == -1 {
+		return true
+	}
+
+	fileNameElements := strings.Split(fileName[underscoreIndex+1:], "_")
+	numFileNameElements := len(fileNameElements)
+
+	if numFileNameElements >= 2 &&
+		knownOS[fileNameElements[numFileNameElements-2]] &&
+		knownArch[fileNameElements[numFileNameElements-1]] {
+		return bc.matchTag(fileNameElements[numFileNameElements-2]) && bc.matchTag(fileNameElements[numFileNameElements-1])
+	}
+
+	lastFileNameElement := fileNameElements[numFileNameElements-1]
+	if knownOS[lastFileNameElement] || knownArch[lastFileNameElement] {
+		return bc.matchTag(lastFileNameElement)
+	}
+
+	return true
+}
+
+// matchTag returns whether a build tag is satisfied under the constraints
+func (bc *BuildConstraints) matchTag(tag string) bool {
+	goos := bc.getGOOS()
+
+	switch {
+	case tag == goos || tag == bc.getGOARCH() || tag == runtime.Compiler:
+		return true
+	case tag == "unix":
+		return unixOS[goos]
+	case tag == "linux" && goos == "android", tag == "darwin" && goos == "ios", tag == "solaris" && goos == "illumos":
+		return true
+	case strings.HasPrefix(tag, "go1."):
+		return true
+	}
+
+	for _, buildTag := range bc.Tags {
+		if tag == buildTag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (bc *BuildConstraints) getGOOS() string {
+	if bc.GOOS == "" {
+		return runtime.GOOS
+	}
+
+	return bc.GOOS
+}
+
+func (bc *BuildConstraints) getGOARCH() string {
+	if bc.GOARCH == "" {
+		return runtime.GOARCH
+	}
+
+	return bc.GOARCH
+}
+
+// getBuildExpr returns the build constraint of a source file, or nil if it has none. A //go:build line
+// takes precedence over // +build lines, which are combined
+func getBuildExpr(source []byte) (constraint.Expr, error) {
+	sourceNode, err := parser.ParseFile(token.NewFileSet(), "", source, parser.PackageClauseOnly|parser.ParseComments)
+
+	// files which fail to parse are verified, so that they're reported as such
+	if err != nil {
+		return nil, nil
+	}
+
+	var goBuildExpr, plusBuildExpr constraint.Expr
+
+	for _, commentGroup := range sourceNode.Comments {
+
+		// constraints must appear before the package clause and be followed by an empty line, so they
+		// can't be part of the package documentation
+		if commentGroup.Pos() > sourceNode.Package || commentGroup == sourceNode.Doc {
+			continue
+		}
+
+		for _, comment := range commentGroup.List {
+			if !constraint.IsGoBuild(comment.Text) && !constraint.IsPlusBuild(comment.Text) {
+				continue
+			}
+
+			buildExpr, err := constraint.Parse(comment.Text)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				goBuildExpr = buildExpr
+			case plusBuildExpr == nil:
+				plusBuildExpr = buildExpr
+			default:
+				plusBuildExpr = &constraint.AndExpr{X: plusBuildExpr, Y: buildExpr}
+			}
+		}
+	}
+
+	if goBuildExpr != nil {
+		return goBuildExpr, nil
+	}
+
+	return plusBuildExpr, nil
+}
@@ -0,0 +1,61 @@
+package impi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BuildConstraintsTestSuite struct {
+	suite.Suite
+	buildConstraints BuildConstraints
+}
+
+func (s *BuildConstraintsTestSuite) SetupTest() {
+	s.buildConstraints = BuildConstraints{
+		Tags:   []string{"integration"},
+		GOOS:   "linux",
+		GOARCH: "amd64",
+	}
+}
+
+func (s *BuildConstraintsTestSuite) TestMatchFileName() {
+	for fileName, expectedMatch := range map[string]bool{
+		"foo.go":                    true,
+		"linux.go":                  true,
+		"windows.go":                true,
+		"foo_linux.go":              true,
+		"foo_linux_test.go":         true,
+		"foo_amd64.go":              true,
+		"foo_linux_amd64.go":        true,
+		"foo_windows.go":            false,
+		"foo_linux_arm64.go":        false,
+		"foo_windows_amd64_test.go": false,
+		"foo_bar.go":                true,
+	} {
+		match, err := s.buildConstraints.matchFile(fileName, []byte("package foo\n"))
+		s.Require().NoError(err)
+		s.Require().Equal(expectedMatch, match, fileName)
+	}
+}
+
+func (s *BuildConstraintsTestSuite) TestMatchBuildLines() {
+	for contents, expectedMatch := range map[string]bool{
+		"package foo\n":                                                  true,
+		"//go:build ignore\n\npackage main\n":                            false,
+		"//go:build linux && !integration\n\npackage foo\n":              false,
+		"//go:build unix && integration\n\npackage foo\n":                true,
+		"//go:build windows\n// +build linux\n\npackage foo\n":           false,
+		"// +build linux darwin\n// +build integration\n\npackage foo\n": true,
+		"// +build linux\n// +build e2e\n\npackage foo\n":                false,
+		"// Package foo does foo\n// +build windows\npackage foo\n":      true,
+	} {
+		match, err := s.buildConstraints.matchFile("foo.go", []byte(contents))
+		s.Require().NoError(err)
+		s.Require().Equal(expectedMatch, match, contents)
+	}
+}
+
+func TestBuildConstraintsTestSuite(t *testing.T) {
+	suite.Run(t, new(BuildConstraintsTestSuite))
+}
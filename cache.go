@@ -0,0 +1,369 @@
+package impi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// resultCacheVersion is part of every key, so that results of previous versions aren't reused once
+// verification changes
+const resultCacheVersion = "5"
+
+// resultCacheTagFileName is the name of the file marking a directory as an impi cache, so that only
+// directories impi created are ever cleaned. Its contents follow https://bford.info/cachedir, which also
+// keeps backup tools from archiving the cache
+const resultCacheTagFileName = "CACHEDIR.TAG"
+
+const resultCacheTagContents = "Signature: 8a477f597d28d172789f06886806bc55\n" +
+	"# This file is a cache directory tag created by impi.\n"
+
+// resultCacheEntryDirRegex matches the directories holding cache entries, named by the first byte of their keys
+var resultCacheEntryDirRegex = regexp.MustCompile(`^[0-9a-f]{2}$`)
+
+// resultCache stores verification results on disk, keyed by the file path, its contents and everything
+// else that affects its verification - the options it's verified with, the files they refer to, the
+// nearest go.mod and the working directory, which relative paths and the module of the local prefix are
+// resolved against
+type resultCache struct {
+	dirPath        string
+	workingDirPath string
+	optionsHashes  map[*VerifyOptions]string
+	goModHashes    map[string]string
+	lock           sync.Mutex
+}
+
+// resultCacheEntry is a cached verification result of a single file
+type resultCacheEntry struct {
+	Violations        []resultCacheViolation `json:"violations,omitempty"`
+	SkipReason        string                 `json:"skip-reason,omitempty"`
+	NumImportsByClass map[string]int         `json:"imports,omitempty"`
+	Imports           []resultCacheImport    `json:"import-names,omitempty"`
+	PackageName       string                 `json:"package,omitempty"`
+	ImportsReportOnly bool                   `json:"import-names-report-only,omitempty"`
+}
+
+// resultCacheImport is an import of the file, cached if it's verified against the other files of the package
+type resultCacheImport struct {
+	Path    string `json:"path"`
+	Name    string `json:"name,omitempty"`
+	LineNum int    `json:"line"`
+}
+
+type resultCacheViolation struct {
+	Rule       string   `json:"rule,omitempty"`
+	LineNum    int      `json:"line,omitempty"`
+	Column     int      `json:"column,omitempty"`
+	Message    string   `json:"message"`
+	ReportOnly bool     `json:"report-only,omitempty"`
+	Severity   Severity `json:"severity,omitempty"`
+}
+
+func newResultCache(dirPath string, verifyOptions *VerifyOptions) (*resultCache, error) {
+
+	// options resolved for files with overrides are hashed as they're first used
+	optionsHash, err := getVerifyOptionsHash(verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	workingDirPath, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return nil, err
+	}
+
+	tagFilePath := filepath.Join(dirPath, resultCacheTagFileName)
+
+	if _, err := os.Stat(tagFilePath); os.IsNotExist(err) {
+		if err := ioutil.WriteFile(tagFilePath, []byte(resultCacheTagContents), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return &resultCache{
+		dirPath:        dirPath,
+		workingDirPath: workingDirPath,
+		optionsHashes:  map[*VerifyOptions]string{verifyOptions: optionsHash},
+		goModHashes:    map[string]string{},
+	}, nil
+}
+
+// get returns the cached result of verifying the file and its statistics, if there is one
+func (rc *resultCache) get(filePath string, source []byte, verifyOptions *VerifyOptions) (*fileStats, ruleViolations, bool) {
+	entryPath, err := rc.getEntryPath(filePath, source, verifyOptions)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	entryContents, err := ioutil.ReadFile(entryPath)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var entry resultCacheEntry
+	if err := json.Unmarshal(entryContents, &entry); err != nil {
+		return nil, nil, false
+	}
+
+	var violations ruleViolations
+
+	for _, cachedViolation := range entry.Violations {
+		violations = append(violations, &ruleViolation{
+			rule:       cachedViolation.Rule,
+			lineNum:    cachedViolation.LineNum,
+			column:     cachedViolation.Column,
+			message:    cachedViolation.Message,
+			reportOnly: cachedViolation.ReportOnly,
+			severity:   cachedViolation.Severity,
+		})
+	}
+
+	cachedFileStats := &fileStats{
+		skipReason:        entry.SkipReason,
+		numImportsByClass: entry.NumImportsByClass,
+		packageName:       entry.PackageName,
+		reportOnly:        entry.ImportsReportOnly,
+	}
+
+	for _, cachedImport := range entry.Imports {
+		cachedFileStats.imports = append(cachedFileStats.imports, fileImport{
+			path:    cachedImport.Path,
+			name:    cachedImport.Name,
+			lineNum: cachedImport.LineNum,
+		})
+	}
+
+	return cachedFileStats, violations, true
+}
+
+// put caches the result of verifying the file and its statistics. Only results of complete verifications - success or
+// rule violations - are cached. Caching is best effort, so failures are ignored
+func (rc *resultCache) put(filePath string,
+	source []byte,
+	verifyOptions *VerifyOptions,
+	fileStats *fileStats,
+	verificationErr error) {
+	violations, ok := verificationErr.(ruleViolations)
+	if verificationErr != nil && !ok {
+		return
+	}
+
+	entry := resultCacheEntry{
+		SkipReason:        fileStats.skipReason,
+		NumImportsByClass: fileStats.numImportsByClass,
+		PackageName:       fileStats.packageName,
+		ImportsReportOnly: fileStats.reportOnly,
+	}
+
+	for _, fileImport := range fileStats.imports {
+		entry.Imports = append(entry.Imports, resultCacheImport{
+			Path:    fileImport.path,
+			Name:    fileImport.name,
+			LineNum: fileImport.lineNum,
+		})
+	}
+
+	for _, violation := range violations {
+		entry.Violations = append(entry.Violations, resultCacheViolation{
+			Rule:       violation.rule,
+			LineNum:    violation.lineNum,
+			Column:     violation.column,
+			Message:    violation.message,
+			ReportOnly: violation.reportOnly,
+			Severity:   violation.severity,
+		})
+	}
+
+	entryContents, err := json.Marshal(&entry)
+	if err != nil {
+		return
+	}
+
+	entryPath, err := rc.getEntryPath(filePath, source, verifyOptions)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0755); err != nil {
+		return
+	}
+
+	// write to a temporary file and rename it, so that concurrent runs never read partial entries
+	entryFile, err := ioutil.TempFile(filepath.Dir(entryPath), ".entry")
+	if err != nil {
+		return
+	}
+
+	_, err = entryFile.Write(entryContents)
+	entryFile.Close()
+
+	if err != nil || os.Rename(entryFile.Name(), entryPath) != nil {
+		os.Remove(entryFile.Name())
+	}
+}
+
+func (rc *resultCache) getEntryPath(filePath string, source []byte, verifyOptions *VerifyOptions) (string, error) {
+	optionsHash, err := rc.getOptionsHash(verifyOptions)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+
+	for _, keyPart := range [][]byte{
+		[]byte(resultCacheVersion),
+		[]byte(optionsHash),
+		[]byte(rc.getGoModHash(filepath.Dir(filePath))),
+		[]byte(rc.workingDirPath),
+		[]byte(verifyOptions.LocalPrefix),
+		[]byte(filePath),
+		source,
+	} {
+		hash.Write(keyPart)
+		hash.Write([]byte{0})
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+
+	return filepath.Join(rc.dirPath, key[:2], key[2:]), nil
+}
+
+// getOptionsHash returns the hash of the options, which are either those of the session or those resolved
+// for files with overrides
+func (rc *resultCache) getOptionsHash(verifyOptions *VerifyOptions) (string, error) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if optionsHash, found := rc.optionsHashes[verifyOptions]; found {
+		return optionsHash, nil
+	}
+
+	optionsHash, err := getVerifyOptionsHash(verifyOptions)
+	if err != nil {
+		return "", err
+	}
+
+	rc.optionsHashes[verifyOptions] = optionsHash
+
+	return optionsHash, nil
+}
+
+// getGoModHash returns the hash of the go.mod nearest to the directory, or an empty string if there's none
+func (rc *resultCache) getGoModHash(dirPath string) string {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if goModHash, found := rc.goModHashes[dirPath]; found {
+		return goModHash
+	}
+
+	goModHash := ""
+
+	if module, err := findGoModule(dirPath); err == nil && module != nil {
+		if goModContents, err := ioutil.ReadFile(filepath.Join(module.rootDir, "go.mod")); err == nil {
+			goModHash = getHash(goModContents)
+		}
+	}
+
+	rc.goModHashes[dirPath] = goModHash
+
+	return goModHash
+}
+
+// getVerifyOptionsHash returns the hash of the options that affect verification results, along with the
+// contents of the files they refer to
+func getVerifyOptionsHash(verifyOptions *VerifyOptions) (string, error) {
+	hashedVerifyOptions := *verifyOptions
+	hashedVerifyOptions.CacheDir = ""
+	hashedVerifyOptions.Fix = false
+
+	encodedVerifyOptions, err := json.Marshal(&hashedVerifyOptions)
+	if err != nil {
+		return "", err
+	}
+
+	for _, thirdPartyAllowlist := range verifyOptions.ThirdPartyAllowlists {
+		if thirdPartyAllowlist.File == "" {
+			continue
+		}
+
+		allowlistContents, err := ioutil.ReadFile(thirdPartyAllowlist.File)
+		if err != nil {
+			return "", err
+		}
+
+		encodedVerifyOptions = append(encodedVerifyOptions, allowlistContents...)
+	}
+
+	return getHash(encodedVerifyOptions), nil
+}
+
+func getHash(contents []byte) string {
+	hash := sha256.Sum256(contents)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// DefaultCacheDir returns the default directory of the result cache: impi under the user's cache
+// directory ($XDG_CACHE_HOME on Linux)
+func DefaultCacheDir() (string, error) {
+	userCacheDirPath, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userCacheDirPath, "impi"), nil
+}
+
+// CleanCache removes all the results cached in the directory, and the directory itself if nothing else is in
+// it. Directories which impi didn't create a cache in are left untouched
+func CleanCache(cacheDirPath string) error {
+	if _, err := os.Stat(cacheDirPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	tagFilePath := filepath.Join(cacheDirPath, resultCacheTagFileName)
+
+	tagContents, err := ioutil.ReadFile(tagFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if string(tagContents) != resultCacheTagContents {
+		return fmt.Errorf("%s is not an impi cache directory", cacheDirPath)
+	}
+
+	fileInfos, err := ioutil.ReadDir(cacheDirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() && resultCacheEntryDirRegex.MatchString(fileInfo.Name()) {
+			if err := os.RemoveAll(filepath.Join(cacheDirPath, fileInfo.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.Remove(tagFilePath); err != nil {
+		return err
+	}
+
+	// whatever else is in the directory wasn't written by impi
+	if fileInfos, err = ioutil.ReadDir(cacheDirPath); err != nil || len(fileInfos) != 0 {
+		return err
+	}
+
+	return os.Remove(cacheDirPath)
+}
@@ -0,0 +1,165 @@
+package impi
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ResultCacheTestSuite struct {
+	suite.Suite
+	tempDir     string
+	options     VerifyOptions
+	resultCache *resultCache
+}
+
+func (s *ResultCacheTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-cache")
+	s.Require().NoError(err)
+
+	s.options = VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+		CacheDir:    s.tempDir,
+	}
+
+	s.resultCache, err = newResultCache(s.tempDir, &s.options)
+	s.Require().NoError(err)
+}
+
+func (s *ResultCacheTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *ResultCacheTestSuite) TestPutGet() {
+	source := []byte("package fixtures\n")
+
+	_, _, found := s.resultCache.get("a.go", source, &s.options)
+	s.Require().False(found)
+
+	// cache success
+	s.resultCache.put("a.go", source, &s.options, &fileStats{numImportsByClass: map[string]int{"std": 2}}, nil)
+
+	stats, violations, found := s.resultCache.get("a.go", source, &s.options)
+	s.Require().True(found)
+	s.Require().Nil(violations)
+	s.Require().Equal(&fileStats{numImportsByClass: map[string]int{"std": 2}}, stats)
+
+	// cache violations of other contents
+	s.resultCache.put("a.go", []byte("package other\n"), &s.options, &fileStats{}, ruleViolations{
+		{rule: ruleDotImport, lineNum: 3, message: "dot import"},
+	})
+
+	_, violations, found = s.resultCache.get("a.go", []byte("package other\n"), &s.options)
+	s.Require().True(found)
+	s.Require().Equal(ruleViolations{{rule: ruleDotImport, lineNum: 3, message: "dot import"}}, violations)
+
+	// other paths and errors which aren't violations aren't cached
+	_, _, found = s.resultCache.get("b.go", source, &s.options)
+	s.Require().False(found)
+
+	s.resultCache.put("c.go", source, &s.options, &fileStats{}, errors.New("expected 'package', found 'EOF'"))
+
+	_, _, found = s.resultCache.get("c.go", source, &s.options)
+	s.Require().False(found)
+}
+
+func (s *ResultCacheTestSuite) TestOptionsChange() {
+	source := []byte("package fixtures\n")
+	s.resultCache.put("a.go", source, &s.options, &fileStats{}, nil)
+
+	// fixing and the cache directory don't affect results
+	s.options.Fix = true
+	s.options.CacheDir = "/somewhere/else"
+
+	resultCache, err := newResultCache(s.tempDir, &s.options)
+	s.Require().NoError(err)
+
+	_, _, found := resultCache.get("a.go", source, &s.options)
+	s.Require().True(found)
+
+	// anything else does
+	s.options.ForbidDotImports = true
+
+	resultCache, err = newResultCache(s.tempDir, &s.options)
+	s.Require().NoError(err)
+
+	_, _, found = resultCache.get("a.go", source, &s.options)
+	s.Require().False(found)
+}
+
+func (s *ResultCacheTestSuite) TestWorkingDirAndLocalPrefixChange() {
+	source := []byte("package fixtures\n")
+	s.resultCache.put("a.go", source, &s.options, &fileStats{}, nil)
+
+	workingDirPath, err := os.Getwd()
+	s.Require().NoError(err)
+
+	defer os.Chdir(workingDirPath)
+
+	// the same relative path in another working directory is another file
+	s.Require().NoError(os.Chdir(s.tempDir))
+
+	resultCache, err := newResultCache(s.tempDir, &s.options)
+	s.Require().NoError(err)
+
+	_, _, found := resultCache.get("a.go", source, &s.options)
+	s.Require().False(found)
+
+	s.Require().NoError(os.Chdir(workingDirPath))
+
+	resultCache, err = newResultCache(s.tempDir, &s.options)
+	s.Require().NoError(err)
+
+	_, _, found = resultCache.get("a.go", source, &s.options)
+	s.Require().True(found)
+
+	// options resolved for the file with another local prefix don't share its results
+	resolvedOptions := s.options
+	resolvedOptions.LocalPrefix = "github.com/pavius/other"
+
+	_, _, found = resultCache.get("a.go", source, &resolvedOptions)
+	s.Require().False(found)
+}
+
+func (s *ResultCacheTestSuite) TestCleanCache() {
+	s.resultCache.put("a.go", []byte("package fixtures\n"), &s.options, &fileStats{}, nil)
+	s.Require().NoError(CleanCache(s.tempDir))
+
+	_, err := os.Stat(s.tempDir)
+	s.Require().True(os.IsNotExist(err))
+
+	// cleaning a cache which was already cleaned does nothing
+	s.Require().NoError(CleanCache(s.tempDir))
+}
+
+func (s *ResultCacheTestSuite) TestCleanCacheKeepsForeignFiles() {
+	s.resultCache.put("a.go", []byte("package fixtures\n"), &s.options, &fileStats{}, nil)
+
+	foreignFilePath := path.Join(s.tempDir, "notes.txt")
+	s.Require().NoError(ioutil.WriteFile(foreignFilePath, []byte("notes"), 0644))
+
+	s.Require().NoError(CleanCache(s.tempDir))
+
+	// only the entries and the tag are removed
+	fileInfos, err := ioutil.ReadDir(s.tempDir)
+	s.Require().NoError(err)
+	s.Require().Len(fileInfos, 1)
+	s.Require().Equal("notes.txt", fileInfos[0].Name())
+
+	// without the tag, the directory isn't a cache and isn't cleaned
+	s.Require().Error(CleanCache(s.tempDir))
+
+	_, err = os.Stat(foreignFilePath)
+	s.Require().NoError(err)
+}
+
+func TestResultCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(ResultCacheTestSuite))
+}
@@ -0,0 +1,88 @@
+package impi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// cgoImportPath is the path of the pseudo-package through which cgo is used. It's not classified by the
+// scheme, and must be imported on its own, directly after its preamble
+const cgoImportPath = "C"
+
+// getCgoImportViolations verifies that `import "C"` is declared on its own and directly follows its preamble,
+// if it has one. An empty line between the preamble and the import turns the preamble into an ordinary
+// comment, which cgo ignores
+func getCgoImportViolations(sourceFileSet *token.FileSet, sourceNode *ast.File) ruleViolations {
+	var violations ruleViolations
+
+	// the end of whatever precedes each declaration, so that comments between declarations can be found
+	previousEnd := sourceNode.Name.End()
+
+	for _, decl := range sourceNode.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			previousEnd = decl.End()
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+
+			if importPath, err := strconv.Unquote(importSpec.Path.Value); err != nil || importPath != cgoImportPath {
+				continue
+			}
+
+			lineNum := sourceFileSet.Position(importSpec.Pos()).Line
+
+			if len(genDecl.Specs) != 1 {
+				violations = append(violations, &ruleViolation{
+					rule:    ruleCgoImport,
+					lineNum: lineNum,
+					message: `import "C" must be declared on its own rather than in a group of imports`,
+				})
+
+				continue
+			}
+
+			if genDecl.Doc != nil || importSpec.Doc != nil {
+				continue
+			}
+
+			// a comment separated from the import by an empty line is most likely a preamble gone astray
+			if commentGroup := getLastCommentGroupBetween(sourceFileSet, sourceNode, previousEnd, genDecl.Pos()); commentGroup != nil {
+				violations = append(violations, &ruleViolation{
+					rule:    ruleCgoImport,
+					lineNum: lineNum,
+					message: fmt.Sprintf(`import "C" must directly follow its preamble, with no empty line in between `+
+						"(comment on line %d)",
+						sourceFileSet.Position(commentGroup.Pos()).Line),
+				})
+			}
+		}
+
+		previousEnd = genDecl.End()
+	}
+
+	return violations
+}
+
+// getLastCommentGroupBetween returns the last comment group of the file which starts on a line following the
+// start position and ends before the end position, if any
+func getLastCommentGroupBetween(sourceFileSet *token.FileSet,
+	sourceNode *ast.File,
+	startPos token.Pos,
+	endPos token.Pos) *ast.CommentGroup {
+	var lastCommentGroup *ast.CommentGroup
+
+	startLineNum := sourceFileSet.Position(startPos).Line
+
+	for _, commentGroup := range sourceNode.Comments {
+		if sourceFileSet.Position(commentGroup.Pos()).Line > startLineNum && commentGroup.End() < endPos {
+			lastCommentGroup = commentGroup
+		}
+	}
+
+	return lastCommentGroup
+}
@@ -0,0 +1,114 @@
+package impi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CgoTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *CgoTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+}
+
+func (s *CgoTestSuite) TestVerify() {
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Preamble",
+			contents: `package fixtures
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"fmt"
+
+	"github.com/pavius/impi/foo"
+)
+`,
+		},
+		{
+			name: "No preamble",
+			contents: `package fixtures
+
+import (
+	"fmt"
+	"os"
+)
+
+import "C"
+`,
+		},
+		{
+			name: "Between groups",
+			contents: `package fixtures
+
+import "fmt" // trailing comment
+
+// #include <stdlib.h>
+import "C"
+
+import "github.com/pavius/impi/foo"
+`,
+		},
+		{
+			name: "Grouped",
+			contents: `package fixtures
+
+import (
+	"fmt"
+	"C"
+	"os"
+
+	"github.com/pavius/impi/foo"
+)
+`,
+			expectedErrorStrings: []string{
+				`import "C" must be declared on its own rather than in a group of imports`,
+			},
+		},
+		{
+			name: "Preamble separated by an empty line",
+			contents: `package fixtures
+
+import "fmt"
+
+// #include <stdlib.h>
+
+import "C"
+`,
+			expectedErrorStrings: []string{
+				`import "C" must directly follow its preamble, with no empty line in between (comment on line 5)`,
+			},
+		},
+	}
+
+	s.verifyTestCases(verificationTestCases)
+}
+
+func (s *CgoTestSuite) TestGroupedNotClassified() {
+	err := s.verify(`package fixtures
+
+import (
+	"fmt"
+
+	"C"
+	"github.com/pavius/impi/foo"
+)
+`)
+	s.Require().Error(err)
+
+	// only the placement of import "C" is reported, rather than a mixed group
+	violations := err.(ruleViolations)
+	s.Require().Len(violations, 1)
+	s.Require().Equal(ruleCgoImport, violations[0].rule)
+	s.Require().Equal(6, violations[0].lineNum)
+}
+
+func TestCgoTestSuite(t *testing.T) {
+	suite.Run(t, new(CgoTestSuite))
+}
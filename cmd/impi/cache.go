@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pavius/impi"
+)
+
+func runCacheCommand(args []string) error {
+	flagSet := flag.NewFlagSet("cache", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s cache clean [--cache-dir <dir>]\n", os.Args[0])
+		flagSet.PrintDefaults()
+	}
+
+	var cacheDir = flagSet.String("cache-dir", "", "directory in which results are cached (default $XDG_CACHE_HOME/impi)")
+
+	if len(args) == 0 || args[0] != "clean" {
+		flagSet.Usage()
+		return &usageError{errors.New("Unsupported cache command")}
+	}
+
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *cacheDir == "" {
+		var err error
+
+		if *cacheDir, err = impi.DefaultCacheDir(); err != nil {
+			return err
+		}
+	}
+
+	return impi.CleanCache(*cacheDir)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/pavius/impi"
+)
+
+func runExplainCommand(args []string) error {
+	flagSet := flag.NewFlagSet("explain", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s explain --scheme <scheme> [--local <local import prefix>] FILE [FILE ...]\n", os.Args[0])
+		flagSet.PrintDefaults()
+	}
+
+	verifyFlags := newVerifyFlags(flagSet)
+
+	verifyOptions, err := verifyFlags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	if verifyOptions.Scheme == impi.ImportGroupVerificationSchemeSingle {
+		return &usageError{errors.New("Verification scheme must be specified")}
+	}
+
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return &usageError{errors.New("No files to explain")}
+	}
+
+	for _, filePath := range flagSet.Args() {
+		importExplanations, err := impi.Explain(filePath, verifyOptions)
+		if err != nil {
+			return &exitError{
+				error:    fmt.Errorf("Failed to explain %s: %s", filePath, err.Error()),
+				exitCode: getExitCode(err),
+			}
+		}
+
+		printImportExplanations(filePath, importExplanations)
+	}
+
+	return nil
+}
+
+func printImportExplanations(filePath string, importExplanations []impi.ImportExplanation) {
+	fmt.Printf("%s:\n", filePath)
+
+	if len(importExplanations) == 0 {
+		fmt.Printf("  no imports\n\n")
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "  LINE\tIMPORT\tGROUP\tTYPE\tEXPECTED\tCLASSIFIED BECAUSE")
+
+	for _, importExplanation := range importExplanations {
+		importString := strconv.Quote(importExplanation.Path)
+		if importExplanation.Name != "" {
+			importString = importExplanation.Name + " " + importString
+		}
+
+		// imports which aren't where the scheme expects them are marked
+		expected := fmt.Sprintf("group %d, #%d", importExplanation.ExpectedGroupIndex, importExplanation.ExpectedIndexInGroup)
+		if importExplanation.GroupIndex != importExplanation.ExpectedGroupIndex {
+			expected += " (moved)"
+		}
+
+		fmt.Fprintf(tabWriter, "  %d\t%s\t%d\t%s\t%s\t%s\n",
+			importExplanation.LineNum,
+			importString,
+			importExplanation.GroupIndex,
+			importExplanation.Type,
+			expected,
+			importExplanation.Reason)
+	}
+
+	tabWriter.Flush()
+	fmt.Println()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pavius/impi"
+)
+
+type stringArrayFlags []string
+
+func (saf *stringArrayFlags) String() string {
+	return strings.Join(*saf, ",")
+}
+
+func (saf *stringArrayFlags) Set(value string) error {
+	*saf = append(*saf, value)
+	return nil
+}
+
+// optionalSchemeFlags sets a scheme which remains nil unless the flag is passed
+type optionalSchemeFlags struct {
+	scheme **impi.ImportGroupVerificationScheme
+}
+
+func (osf *optionalSchemeFlags) String() string {
+	if osf.scheme == nil || *osf.scheme == nil {
+		return ""
+	}
+
+	return (*osf.scheme).String()
+}
+
+func (osf *optionalSchemeFlags) Set(name string) error {
+	var scheme impi.ImportGroupVerificationScheme
+
+	if err := scheme.Set(name); err != nil {
+		return err
+	}
+
+	*osf.scheme = &scheme
+	return nil
+}
+
+// severityFlags sets the severities of rules, each passed as <rule>=<severity>
+type severityFlags struct {
+	severities *map[string]impi.Severity
+}
+
+func (sf *severityFlags) String() string {
+	if sf.severities == nil {
+		return ""
+	}
+
+	var ruleSeverities []string
+
+	for rule, severity := range *sf.severities {
+		ruleSeverities = append(ruleSeverities, fmt.Sprintf("%s=%s", rule, severity))
+	}
+
+	sort.Strings(ruleSeverities)
+
+	return strings.Join(ruleSeverities, ",")
+}
+
+func (sf *severityFlags) Set(value string) error {
+	ruleSeverity := strings.SplitN(value, "=", 2)
+	if len(ruleSeverity) != 2 {
+		return fmt.Errorf("Expected <rule>=<severity>, got %s", value)
+	}
+
+	var severity impi.Severity
+
+	if err := severity.Set(ruleSeverity[1]); err != nil {
+		return err
+	}
+
+	if *sf.severities == nil {
+		*sf.severities = map[string]impi.Severity{}
+	}
+
+	(*sf.severities)[ruleSeverity[0]] = severity
+	return nil
+}
+
+// verifyFlags are the flags specifying verification options, shared by all the commands which verify files
+type verifyFlags struct {
+	flagSet       *flag.FlagSet
+	verifyOptions impi.VerifyOptions
+	configPath    *string
+	buildTags     *string
+	goos          *string
+	goarch        *string
+	allFiles      *bool
+	noCache       *bool
+}
+
+func newVerifyFlags(flagSet *flag.FlagSet) *verifyFlags {
+	vf := &verifyFlags{
+		flagSet: flagSet,
+	}
+
+	verifyOptions := &vf.verifyOptions
+
+	vf.configPath = flagSet.String("config", "", "path to a JSON configuration file. flags override its options (default .impi.json, if it exists)")
+	flagSet.StringVar(&verifyOptions.LocalPrefix, "local", "", "prefix of the local repository")
+	flagSet.Var(&verifyOptions.Scheme, "scheme", "verification scheme to enforce. one of stdLocalThirdParty/stdThirdPartyLocal")
+	flagSet.Var(&verifyOptions.Tests, "tests", "how to verify _test.go files. one of check/skip/report-only")
+	flagSet.Var(&optionalSchemeFlags{scheme: &verifyOptions.TestScheme}, "test-scheme", "verification scheme to enforce in _test.go files (default --scheme)")
+	flagSet.Var(&verifyOptions.PackageUnderTest, "package-under-test", "where external test packages import the package they test. one of local/first-local/last-group")
+	flagSet.Var(&verifyOptions.SortOrder, "sort-order", "how imports are sorted within a group. one of lexical/goimports/case-insensitive/parent-first/alias/alias-first")
+	flagSet.BoolVar(&verifyOptions.IgnoreGenerated, "ignore-generated", false, "ignore files generated by 'go generate'. same as --generated=skip")
+	flagSet.BoolVar(&verifyOptions.SkipUnparseable, "skip-unparseable", false, "skip files which fail to parse rather than reporting them")
+	flagSet.Var(&verifyOptions.Generated, "generated", "how to verify generated files. one of check/skip/report-only")
+	flagSet.Var((*stringArrayFlags)(&verifyOptions.GeneratedMarkers), "generated-marker", "comment marking files as generated, in addition to the standard one (regex)")
+	flagSet.BoolVar(&verifyOptions.CommentGroupBoundaries, "comment-group-boundaries", false, "treat comment lines following imports as the start of a new group")
+	flagSet.Var((*stringArrayFlags)(&verifyOptions.RequireGroupHeaders), "require-group-headers", "class of imports whose groups must start with a header comment. one of std/local/third-party/local-or-third-party")
+	flagSet.BoolVar(&verifyOptions.ForbidTrailingComments, "forbid-trailing-comments", false, "forbid comments following imports on the same line")
+	flagSet.BoolVar(&verifyOptions.ForbidDotImports, "forbid-dot-imports", false, "forbid dot imports outside of tests")
+	flagSet.BoolVar(&verifyOptions.RequireBlankImportComment, "require-blank-import-comment", false, "require blank imports to be justified by a comment")
+	flagSet.BoolVar(&verifyOptions.BlankImportsLast, "blank-imports-last", false, "require blank imports to reside in a dedicated, trailing group")
+	flagSet.BoolVar(&verifyOptions.ForbidRedundantAliases, "forbid-redundant-aliases", false, "forbid aliases identical to the package name")
+	flagSet.BoolVar(&verifyOptions.ForbidDuplicateImports, "forbid-duplicate-imports", false, "forbid importing the same path more than once in a file")
+	flagSet.BoolVar(&verifyOptions.RequireConsistentAliases, "require-consistent-aliases", false, "require the files of a package to import each path under the same name")
+	flagSet.BoolVar(&verifyOptions.RequireLowercaseAliases, "require-lowercase-aliases", false, "require aliases to be lowercase, without underscores")
+	flagSet.BoolVar(&verifyOptions.RequireAliases, "require-aliases", false, "require aliases for import paths that don't imply the package name")
+	flagSet.BoolVar(&verifyOptions.EnforceInternalImports, "enforce-internal-imports", false, "apply Go's visibility rules to imports of internal packages")
+	flagSet.Var(&severityFlags{severities: &verifyOptions.Severities}, "severity", "severity of the findings of a rule, as <rule>=<severity>. severity is one of error/warning/info/off")
+	flagSet.Var(&verifyOptions.FailOn, "fail-on", "least severe findings which fail verification. one of error/warning/info")
+	flagSet.BoolVar(&verifyOptions.Fix, "fix", false, "rewrite the imports of files which fail verification, where possible")
+	flagSet.Var((*stringArrayFlags)(&verifyOptions.SkipPaths), "skip", "paths to skip (regex)")
+	flagSet.BoolVar(&verifyOptions.DisableGitignore, "disable-gitignore", false, "verify files even if .gitignore files ignore them")
+	flagSet.BoolVar(&verifyOptions.IncludeVendor, "include-vendor", false, "verify packages in vendor directories")
+	flagSet.BoolVar(&verifyOptions.IncludeNestedModules, "include-nested-modules", false, "verify packages of nested modules")
+
+	vf.buildTags = flagSet.String("tags", "", "comma separated build tags. only files which would be built are verified")
+	vf.goos = flagSet.String("goos", "", "target operating system. only files which would be built are verified")
+	vf.goarch = flagSet.String("goarch", "", "target architecture. only files which would be built are verified")
+	vf.allFiles = flagSet.Bool("all-files", false, "verify all files even if the configuration file sets build constraints. without constraints, all files are verified anyway")
+
+	flagSet.StringVar(&verifyOptions.CacheDir, "cache-dir", "", "directory in which results are cached (default $XDG_CACHE_HOME/impi)")
+	vf.noCache = flagSet.Bool("no-cache", false, "don't cache results")
+
+	return vf
+}
+
+// parse parses the arguments into verification options, on top of those of the configuration file if
+// there is one
+func (vf *verifyFlags) parse(args []string) (*impi.VerifyOptions, error) {
+	if err := vf.flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+
+	verifyOptions := &vf.verifyOptions
+
+	// the configuration file of the working directory is read unless another is given
+	configPath := *vf.configPath
+	if configPath == "" {
+		if _, err := os.Stat(impi.DefaultConfigFileName); err == nil {
+			configPath = impi.DefaultConfigFileName
+		}
+	}
+
+	// if there's a configuration file, read it and parse the flags again so that they take precedence
+	if configPath != "" {
+		*verifyOptions = impi.VerifyOptions{}
+
+		if err := impi.ReadVerifyOptionsFile(configPath, verifyOptions); err != nil {
+			return nil, err
+		}
+
+		if err := vf.flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+	}
+
+	// build constraints flags override those of the configuration file
+	if *vf.buildTags != "" || *vf.goos != "" || *vf.goarch != "" {
+		if verifyOptions.BuildConstraints == nil {
+			verifyOptions.BuildConstraints = &impi.BuildConstraints{}
+		}
+
+		if *vf.buildTags != "" {
+			verifyOptions.BuildConstraints.Tags = strings.Split(*vf.buildTags, ",")
+		}
+
+		if *vf.goos != "" {
+			verifyOptions.BuildConstraints.GOOS = *vf.goos
+		}
+
+		if *vf.goarch != "" {
+			verifyOptions.BuildConstraints.GOARCH = *vf.goarch
+		}
+	}
+
+	if *vf.allFiles {
+		verifyOptions.BuildConstraints = nil
+	}
+
+	// results are cached by default, if there's somewhere to cache them
+	if *vf.noCache {
+		verifyOptions.CacheDir = ""
+	} else if verifyOptions.CacheDir == "" {
+		verifyOptions.CacheDir, _ = impi.DefaultCacheDir()
+	}
+
+	return verifyOptions, nil
+}
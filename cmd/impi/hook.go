@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pavius/impi"
+)
+
+// hookMarker identifies pre-commit hooks installed by impi, so that hooks written by others are never
+// overwritten or removed
+const hookMarker = "# installed by impi hook install"
+
+// hook modes - whether the hook only verifies the staged files or fixes them as well
+const (
+	hookModeCheck = "check"
+	hookModeFix   = "fix"
+)
+
+func runHookCommand(args []string) error {
+	if len(args) != 0 {
+		switch args[0] {
+		case "install":
+			return runHookInstallCommand(args[1:])
+		case "uninstall":
+			return runHookUninstallCommand(args[1:])
+		case "run":
+			return runHookRunCommand(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "usage: %s hook install [--mode check|fix] [--restage] [--force] [-- <verify flags>]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s hook uninstall\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s hook run [--mode check|fix] [--restage] <verify flags>\n", os.Args[0])
+
+	return &usageError{errors.New("Unsupported hook command")}
+}
+
+// runHookInstallCommand writes a pre-commit hook which runs impi on the staged go files. Verify flags following
+// the hook flags (e.g. --config .impi.json) are passed to every run of the hook
+func runHookInstallCommand(args []string) error {
+	flagSet := flag.NewFlagSet("hook install", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s hook install [--mode check|fix] [--restage] [--force] [-- <verify flags>]\n", os.Args[0])
+		flagSet.PrintDefaults()
+	}
+
+	var mode = flagSet.String("mode", hookModeCheck, "whether the hook verifies the staged files or fixes them as well. one of check/fix")
+	var restage = flagSet.Bool("restage", false, "stage the files the hook fixes. only applies to fix mode")
+	var force = flagSet.Bool("force", false, "overwrite a pre-commit hook which wasn't installed by impi")
+
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := verifyHookMode(*mode); err != nil {
+		return err
+	}
+
+	if *restage && *mode != hookModeFix {
+		return &usageError{errors.New("Only fixed files can be re-staged")}
+	}
+
+	hookPath, err := getPreCommitHookPath()
+	if err != nil {
+		return err
+	}
+
+	if !*force && isForeignHook(hookPath) {
+		return &usageError{fmt.Errorf("%s wasn't installed by impi. Pass --force to overwrite it", hookPath)}
+	}
+
+	// the hook falls back to this very binary if impi isn't in the PATH it runs with
+	executablePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	hookArgs := []string{"hook", "run", "--mode", *mode}
+	if *restage {
+		hookArgs = append(hookArgs, "--restage")
+	}
+
+	hookContents := getHookContents(executablePath, append(hookArgs, flagSet.Args()...))
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(hookPath, []byte(hookContents), 0755); err != nil {
+		return err
+	}
+
+	// the file may have existed with other permissions
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return err
+	}
+
+	fmt.Printf("Installed pre-commit hook at %s\n", hookPath)
+
+	return nil
+}
+
+// runHookUninstallCommand removes the pre-commit hook, if impi installed it
+func runHookUninstallCommand(args []string) error {
+	if len(args) != 0 {
+		return &usageError{fmt.Errorf("Unexpected arguments: %s", strings.Join(args, " "))}
+	}
+
+	hookPath, err := getPreCommitHookPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(hookPath); os.IsNotExist(err) {
+		return &usageError{fmt.Errorf("No pre-commit hook is installed at %s", hookPath)}
+	}
+
+	if isForeignHook(hookPath) {
+		return &usageError{fmt.Errorf("%s wasn't installed by impi", hookPath)}
+	}
+
+	if err := os.Remove(hookPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed pre-commit hook at %s\n", hookPath)
+
+	return nil
+}
+
+// runHookRunCommand verifies the staged contents of the staged go files, fixing those without unstaged changes
+// in fix mode. It's what the installed hook runs
+func runHookRunCommand(args []string) error {
+	flagSet := flag.NewFlagSet("hook run", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s hook run [--mode check|fix] [--restage] <verify flags>\n", os.Args[0])
+		flagSet.PrintDefaults()
+	}
+
+	verifyFlags := newVerifyFlags(flagSet)
+
+	var mode = flagSet.String("mode", hookModeCheck, "whether to verify the staged files or fix them as well. one of check/fix")
+	var restage = flagSet.Bool("restage", false, "stage the files which are fixed. files with unstaged changes are never fixed")
+
+	verifyOptions, err := verifyFlags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyHookMode(*mode); err != nil {
+		return err
+	}
+
+	if verifyOptions.Scheme == impi.ImportGroupVerificationSchemeSingle {
+		return &usageError{errors.New("Verification scheme must be specified")}
+	}
+
+	// hooks run in the root of the work tree, where staged paths are relative to
+	stagedFilePaths, err := getGitPaths("diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z", "--", "*.go")
+	if err != nil {
+		return err
+	}
+
+	if len(stagedFilePaths) == 0 {
+		return nil
+	}
+
+	// the staged contents are what's committed, so they're what's verified
+	stagedContentsByFilePath := map[string][]byte{}
+
+	for _, filePath := range stagedFilePaths {
+		stagedContents, err := runGit("show", ":"+filePath)
+		if err != nil {
+			return err
+		}
+
+		stagedContentsByFilePath[filePath] = []byte(stagedContents)
+	}
+
+	// files are fixed in the work tree, so only files without unstaged changes can be - fixing the others
+	// would mix their unstaged changes into the fix
+	var fixedFilePaths, checkedFilePaths []string
+
+	for _, filePath := range stagedFilePaths {
+		if *mode == hookModeFix && !hasUnstagedChanges(filePath, stagedContentsByFilePath[filePath]) {
+			fixedFilePaths = append(fixedFilePaths, filePath)
+		} else {
+			checkedFilePaths = append(checkedFilePaths, filePath)
+		}
+	}
+
+	if *mode == hookModeFix && len(checkedFilePaths) != 0 {
+		fmt.Printf("Not fixing files with unstaged changes: %s\n", strings.Join(checkedFilePaths, ", "))
+	}
+
+	stats := impi.Stats{}
+	numErrors := 0
+
+	checkVerifyOptions := *verifyOptions
+	checkVerifyOptions.Fix = false
+
+	fixVerifyOptions := *verifyOptions
+	fixVerifyOptions.Fix = true
+
+	// files without unstaged changes are the same on disk as in the index, so they're read from disk
+	for _, hookRun := range []struct {
+		filePaths     []string
+		readFile      func(filePath string) ([]byte, error)
+		verifyOptions *impi.VerifyOptions
+	}{
+		{
+			filePaths: checkedFilePaths,
+			readFile: func(filePath string) ([]byte, error) {
+				return stagedContentsByFilePath[filePath], nil
+			},
+			verifyOptions: &checkVerifyOptions,
+		},
+		{
+			filePaths:     fixedFilePaths,
+			verifyOptions: &fixVerifyOptions,
+		},
+	} {
+		if len(hookRun.filePaths) == 0 {
+			continue
+		}
+
+		impiInstance, err := impi.NewImpi(runtime.NumCPU())
+		if err != nil {
+			return fmt.Errorf("Failed to create impi: %s", err.Error())
+		}
+
+		err = impiInstance.VerifyFiles(hookRun.filePaths, hookRun.readFile, hookRun.verifyOptions, &consoleErrorReporter{})
+
+		runStats := impiInstance.GetStats()
+		stats.Add(&runStats)
+
+		// keep verifying the other files, so that all the violations are reported at once
+		if violationsErr, ok := err.(*impi.ViolationsError); ok {
+			numErrors += violationsErr.NumErrors
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if numErrors != 0 {
+		return getRunError(&impi.ViolationsError{NumErrors: numErrors}, &stats)
+	}
+
+	// the commit would hold the files as they were before they were fixed, unless they're staged again
+	unstagedFilePaths, err := getUnstagedFixedFilePaths(fixedFilePaths, stagedContentsByFilePath, *restage)
+	if err != nil {
+		return err
+	}
+
+	if len(unstagedFilePaths) != 0 {
+		return &exitError{
+			error:    fmt.Errorf("Fixed files which aren't staged, stage them and commit again: %s", strings.Join(unstagedFilePaths, ", ")),
+			exitCode: exitCodeViolations,
+		}
+	}
+
+	return nil
+}
+
+// getHookContents returns the script of a hook which runs impi with the arguments. impi is looked up in the PATH
+// the hook runs with, so that the hook keeps working as impi is upgraded or moved, and the executable is only
+// run if it isn't there
+func getHookContents(executablePath string, hookArgs []string) string {
+	var quotedHookArgs []string
+	for _, hookArg := range hookArgs {
+		quotedHookArgs = append(quotedHookArgs, quoteShellArg(hookArg))
+	}
+
+	return fmt.Sprintf("#!/bin/sh\n%s. remove with impi hook uninstall\nimpi=$(command -v impi) || impi=%s\nexec \"$impi\" %s\n",
+		hookMarker,
+		quoteShellArg(executablePath),
+		strings.Join(quotedHookArgs, " "))
+}
+
+// getUnstagedFixedFilePaths returns the files which were fixed, which is told by their contents no longer
+// matching those staged, staging them first if restage is set. Only files without unstaged changes are fixed,
+// so staging them stages nothing but the fix
+func getUnstagedFixedFilePaths(fixedFilePaths []string, stagedContentsByFilePath map[string][]byte, restage bool) ([]string, error) {
+	var restagedFilePaths, unstagedFilePaths []string
+
+	for _, filePath := range fixedFilePaths {
+		fixedContents, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(stagedContentsByFilePath[filePath], fixedContents) {
+			continue
+		}
+
+		if restage {
+			restagedFilePaths = append(restagedFilePaths, filePath)
+		} else {
+			unstagedFilePaths = append(unstagedFilePaths, filePath)
+		}
+	}
+
+	if len(restagedFilePaths) != 0 {
+		if _, err := runGit(append([]string{"add", "--"}, restagedFilePaths...)...); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(unstagedFilePaths)
+
+	return unstagedFilePaths, nil
+}
+
+// hasUnstagedChanges returns whether the work tree copy of the file differs from its staged contents
+func hasUnstagedChanges(filePath string, stagedContents []byte) bool {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return true
+	}
+
+	return !bytes.Equal(contents, stagedContents)
+}
+
+// getPreCommitHookPath returns the path of the pre-commit hook of the repository of the working directory,
+// honoring core.hooksPath
+func getPreCommitHookPath() (string, error) {
+	hooksDirPath, err := runGit("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(strings.TrimSpace(hooksDirPath), "pre-commit"), nil
+}
+
+// isForeignHook returns whether a hook exists at the path and wasn't installed by impi
+func isForeignHook(hookPath string) bool {
+	hookContents, err := ioutil.ReadFile(hookPath)
+	if err != nil {
+		return !os.IsNotExist(err)
+	}
+
+	return !bytes.Contains(hookContents, []byte(hookMarker))
+}
+
+func verifyHookMode(mode string) error {
+	if mode != hookModeCheck && mode != hookModeFix {
+		return &usageError{fmt.Errorf("Unsupported hook mode: %s", mode)}
+	}
+
+	return nil
+}
+
+// getGitPaths runs git with arguments which make it print NUL separated paths, and returns the paths
+func getGitPaths(args ...string) ([]string, error) {
+	output, err := runGit(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// runGit runs git in the working directory and returns its output
+func runGit(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	command := exec.Command("git", args...)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// quoteShellArg quotes an argument for sh, in single quotes
+func quoteShellArg(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// misorderedContents are the contents of a file the fix reorders
+const misorderedContents = `package fixtures
+
+import (
+	"github.com/some/thirdparty"
+	"fmt"
+)
+`
+
+const fixedContents = `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/some/thirdparty"
+)
+`
+
+type HookTestSuite struct {
+	suite.Suite
+	tempDir        string
+	workingDirPath string
+}
+
+func (s *HookTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-hook")
+	s.Require().NoError(err)
+
+	s.workingDirPath, err = os.Getwd()
+	s.Require().NoError(err)
+
+	// hooks run in the root of the work tree
+	s.Require().NoError(os.Chdir(s.tempDir))
+
+	_, err = runGit("init", "--quiet")
+	s.Require().NoError(err)
+}
+
+func (s *HookTestSuite) TearDownTest() {
+	os.Chdir(s.workingDirPath)
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *HookTestSuite) TestHookContents() {
+	hookContents := getHookContents("/opt/impi's/impi", []string{"hook", "run", "--mode", "fix", "--config", "it's.json"})
+
+	s.Require().Equal(`#!/bin/sh
+# installed by impi hook install. remove with impi hook uninstall
+impi=$(command -v impi) || impi='/opt/impi'\''s/impi'
+exec "$impi" 'hook' 'run' '--mode' 'fix' '--config' 'it'\''s.json'
+`, hookContents)
+}
+
+func (s *HookTestSuite) TestHookResolvesImpi() {
+	binDirPath := path.Join(s.tempDir, "bin")
+	fallbackDirPath := path.Join(s.tempDir, "fallback")
+
+	// both impis print how they were run
+	for _, dirPath := range []string{binDirPath, fallbackDirPath} {
+		s.Require().NoError(os.MkdirAll(dirPath, 0755))
+		s.Require().NoError(ioutil.WriteFile(path.Join(dirPath, "impi"),
+			[]byte("#!/bin/sh\necho \"$0 $*\"\n"), 0755))
+	}
+
+	hookPath := path.Join(s.tempDir, "pre-commit")
+	s.Require().NoError(ioutil.WriteFile(hookPath,
+		[]byte(getHookContents(path.Join(fallbackDirPath, "impi"), []string{"hook", "run"})), 0755))
+
+	// impi is taken from the PATH, and the executable which installed the hook is only run if it's not there
+	for pathEnv, expectedOutput := range map[string]string{
+		binDirPath + ":/usr/bin:/bin": path.Join(binDirPath, "impi") + " hook run\n",
+		"/usr/bin:/bin":               path.Join(fallbackDirPath, "impi") + " hook run\n",
+	} {
+		command := exec.Command("/bin/sh", hookPath)
+		command.Env = []string{"PATH=" + pathEnv}
+
+		output, err := command.Output()
+		s.Require().NoError(err, pathEnv)
+		s.Require().Equal(expectedOutput, string(output), pathEnv)
+	}
+}
+
+func (s *HookTestSuite) TestForeignHook() {
+	hookPath, err := getPreCommitHookPath()
+	s.Require().NoError(err)
+
+	// no hook isn't a foreign one
+	s.Require().False(isForeignHook(hookPath))
+
+	foreignHookContents := []byte("#!/bin/sh\nmake lint\n")
+
+	s.Require().NoError(os.MkdirAll(path.Dir(hookPath), 0755))
+	s.Require().NoError(ioutil.WriteFile(hookPath, foreignHookContents, 0755))
+	s.Require().True(isForeignHook(hookPath))
+
+	// hooks which impi didn't install are neither overwritten nor removed
+	s.Require().IsType(&usageError{}, runHookInstallCommand(nil))
+	s.Require().IsType(&usageError{}, runHookUninstallCommand(nil))
+
+	hookContents, err := ioutil.ReadFile(hookPath)
+	s.Require().NoError(err)
+	s.Require().Equal(foreignHookContents, hookContents)
+
+	// unless forced to
+	s.Require().NoError(runHookInstallCommand([]string{"--force"}))
+	s.Require().False(isForeignHook(hookPath))
+
+	s.Require().NoError(runHookUninstallCommand(nil))
+
+	_, err = os.Stat(hookPath)
+	s.Require().True(os.IsNotExist(err))
+}
+
+func (s *HookTestSuite) TestFixOnlyFilesWithoutUnstagedChanges() {
+	for _, fileName := range []string{"clean.go", "dirty.go"} {
+		s.Require().NoError(ioutil.WriteFile(fileName, []byte(misorderedContents), 0644))
+	}
+
+	_, err := runGit("add", "--", "clean.go", "dirty.go")
+	s.Require().NoError(err)
+
+	dirtyContents := misorderedContents + "\nfunc unstaged() {}\n"
+	s.Require().NoError(ioutil.WriteFile("dirty.go", []byte(dirtyContents), 0644))
+
+	s.Require().False(hasUnstagedChanges("clean.go", []byte(misorderedContents)))
+	s.Require().True(hasUnstagedChanges("dirty.go", []byte(misorderedContents)))
+
+	// the file with unstaged changes is only checked, failing the commit
+	err = runHookRunCommand([]string{"--mode", "fix", "--restage", "--scheme", "stdLocalThirdParty", "--local", "github.com/pavius/impi"})
+	s.Require().Error(err)
+	s.Require().Equal(exitCodeViolations, getExitCode(err))
+
+	cleanContents, err := ioutil.ReadFile("clean.go")
+	s.Require().NoError(err)
+	s.Require().Equal(fixedContents, string(cleanContents))
+
+	contents, err := ioutil.ReadFile("dirty.go")
+	s.Require().NoError(err)
+	s.Require().Equal(dirtyContents, string(contents))
+
+	// fixed files are reported unless they're staged again
+	stagedContentsByFilePath := map[string][]byte{"clean.go": []byte(misorderedContents)}
+
+	unstagedFilePaths, err := getUnstagedFixedFilePaths([]string{"clean.go"}, stagedContentsByFilePath, false)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"clean.go"}, unstagedFilePaths)
+
+	unstagedFilePaths, err = getUnstagedFixedFilePaths([]string{"clean.go"}, stagedContentsByFilePath, true)
+	s.Require().NoError(err)
+	s.Require().Empty(unstagedFilePaths)
+
+	stagedContents, err := runGit("show", ":clean.go")
+	s.Require().NoError(err)
+	s.Require().Equal(fixedContents, stagedContents)
+}
+
+func TestHookTestSuite(t *testing.T) {
+	suite.Run(t, new(HookTestSuite))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/pavius/impi"
+)
+
+func runInferCommand(args []string) error {
+	flagSet := flag.NewFlagSet("infer", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s infer [--local <local import prefix>] [--output <config>] PACKAGE [PACKAGE ...]\n", os.Args[0])
+		flagSet.PrintDefaults()
+	}
+
+	verifyFlags := newVerifyFlags(flagSet)
+	var outputPath = flagSet.String("output", "", "path of the starter configuration file to write. empty to print it")
+
+	verifyOptions, err := verifyFlags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return &usageError{errors.New("No packages to infer from")}
+	}
+
+	// never overwrite an existing configuration, which is refused before spending the time to infer
+	if *outputPath != "" {
+		if _, err := os.Stat(*outputPath); err == nil {
+			return &usageError{fmt.Errorf("%s already exists, pass another --output to write a starter configuration", *outputPath)}
+		}
+	}
+
+	schemeConformances, err := impi.InferSchemes(flagSet.Args(), verifyOptions, runtime.NumCPU())
+	if err != nil {
+		return err
+	}
+
+	tabWriter := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "SCHEME\tLOCAL PREFIX\tCONFORMING FILES")
+
+	for _, schemeConformance := range schemeConformances {
+		localPrefix := schemeConformance.LocalPrefix
+		if localPrefix == "" {
+			localPrefix = "(none)"
+		}
+
+		scheme := schemeConformance.Scheme.String()
+		if schemeConformance.IsConfigured() {
+			scheme += " (as configured)"
+		}
+
+		fmt.Fprintf(tabWriter, "%s\t%s\t%d/%d (%.1f%%)\n",
+			scheme,
+			localPrefix,
+			schemeConformance.NumConformingFiles,
+			schemeConformance.NumFiles,
+			schemeConformance.GetPercentage())
+	}
+
+	tabWriter.Flush()
+
+	bestSchemeConformance := schemeConformances[0]
+	fmt.Printf("\nMost files follow %s", bestSchemeConformance.Scheme)
+	if bestSchemeConformance.LocalPrefix != "" {
+		fmt.Printf(" with the local prefix %s", bestSchemeConformance.LocalPrefix)
+	}
+
+	if bestSchemeConformance.IsConfigured() {
+		fmt.Printf(", with the configured sub-groups and sort order")
+	}
+
+	fmt.Println()
+
+	starterVerifyOptions := &impi.VerifyOptions{
+		Scheme:      bestSchemeConformance.Scheme,
+		LocalPrefix: bestSchemeConformance.LocalPrefix,
+		Subgroups:   bestSchemeConformance.Subgroups,
+		SortOrder:   bestSchemeConformance.SortOrder,
+	}
+
+	if *outputPath == "" {
+		starterConfigContents, err := impi.EncodeVerifyOptions(starterVerifyOptions)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\nStarter configuration (save as %s, or pass --output to write it):\n%s",
+			impi.DefaultConfigFileName,
+			starterConfigContents)
+
+		return nil
+	}
+
+	if err := impi.WriteVerifyOptionsFile(*outputPath, starterVerifyOptions); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote a starter configuration to %s\n", *outputPath)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InferCommandTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (s *InferCommandTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-infer-command")
+	s.Require().NoError(err)
+}
+
+func (s *InferCommandTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *InferCommandTestSuite) TestExistingOutput() {
+	outputPath := path.Join(s.tempDir, ".impi.json")
+	s.Require().NoError(ioutil.WriteFile(outputPath, []byte("{}\n"), 0644))
+
+	// the output is refused before inferring, so packages which don't exist aren't reached
+	err := runInferCommand([]string{"--output", outputPath, path.Join(s.tempDir, "nothing")})
+	s.Require().IsType(&usageError{}, err)
+	s.Require().Contains(err.Error(), "already exists")
+
+	contents, err := ioutil.ReadFile(outputPath)
+	s.Require().NoError(err)
+	s.Require().Equal("{}\n", string(contents))
+}
+
+func (s *InferCommandTestSuite) TestOutput() {
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "a.go"), []byte(`package fixtures
+
+import (
+	"fmt"
+
+	"github.com/pavius/impi/a"
+)
+`), 0644))
+
+	outputPath := path.Join(s.tempDir, "starter.json")
+
+	s.Require().NoError(runInferCommand([]string{"--local", "github.com/pavius/impi", "--output", outputPath, s.tempDir}))
+
+	contents, err := ioutil.ReadFile(outputPath)
+	s.Require().NoError(err)
+	s.Require().Contains(string(contents), `"scheme": "stdLocalThirdParty"`)
+}
+
+func TestInferCommandTestSuite(t *testing.T) {
+	suite.Run(t, new(InferCommandTestSuite))
+}
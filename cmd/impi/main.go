@@ -1,63 +1,91 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"runtime"
-	"strings"
 
 	"github.com/pavius/impi"
 )
 
-type consoleErrorReporter struct{}
-
-type stringArrayFlags []string
+// exit codes tell violations apart from errors in how impi was run and from files it failed to verify
+const (
+	exitCodeViolations = 1
+	exitCodeUsage      = 2
+	exitCodeFailure    = 3
+)
 
-func (saf *stringArrayFlags) String() string {
-	return strings.Join(*saf, ",")
+// exitError is an error which exits with a specific code. Other errors are taken as failures, unless they're
+// usage or configuration errors
+type exitError struct {
+	error
+	exitCode int
 }
 
-func (saf *stringArrayFlags) Set(value string) error {
-	*saf = append(*saf, value)
-	return nil
+// usageError is an error in how impi was run - its command, arguments or flags
+type usageError struct {
+	error
 }
 
+type consoleErrorReporter struct{}
+
 func (cer *consoleErrorReporter) Report(err impi.VerificationError) {
-	fmt.Printf("%s: %s\n", err.FilePath, err.Error())
+	fmt.Println(formatVerificationError(err))
 }
 
-func getVerificationSchemeType(scheme string) (impi.ImportGroupVerificationScheme, error) {
-	switch scheme {
-	case "stdLocalThirdParty":
-		return impi.ImportGroupVerificationSchemeStdLocalThirdParty, nil
-	case "stdThirdPartyLocal":
-		return impi.ImportGroupVerificationSchemeStdThirdPartyLocal, nil
-	default:
-		return 0, fmt.Errorf("Unsupported verification scheme: %s", scheme)
+func formatVerificationError(err impi.VerificationError) string {
+	message := err.Error()
+	if err.Severity != impi.SeverityError {
+		message += fmt.Sprintf(" (%s)", err.Severity)
+	}
+
+	if err.ReportOnly {
+		message += " (report only)"
+	}
+
+	if err.Column != 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", err.FilePath, err.LineNum, err.Column, message)
+	}
+
+	if err.LineNum != 0 {
+		return fmt.Sprintf("%s:%d: %s", err.FilePath, err.LineNum, message)
 	}
+
+	return fmt.Sprintf("%s: %s", err.FilePath, message)
 }
 
 func run() error {
+	verifyFlags := newVerifyFlags(flag.CommandLine)
 
-	var localPrefix = flag.String("local", "", "prefix of the local repository")
-	var scheme = flag.String("scheme", "", "verification scheme to enforce. one of stdLocalThirdParty/stdThirdPartyLocal")
-	var ignoreGenerated = flag.Bool("ignore-generated", false, "ignore files generated by 'go generate'")
-
-	var skipPaths stringArrayFlags
-	flag.Var(&skipPaths, "skip", "paths to skip (regex)")
+	var summary = flag.String("summary", "", "print a summary of the run at its end. one of text/json")
+	var watch = flag.Bool("watch", false, "keep verifying files as they change, showing the current violations")
 
 	numCPUs := runtime.NumCPU()
 	runtime.GOMAXPROCS(numCPUs)
 
 	// parse flags
-	flag.Parse()
-
-	verificationScheme, err := getVerificationSchemeType(*scheme)
+	verifyOptions, err := verifyFlags.parse(os.Args[1:])
 	if err != nil {
 		return err
 	}
 
+	if verifyOptions.Scheme == impi.ImportGroupVerificationSchemeSingle {
+		return &usageError{errors.New("Verification scheme must be specified")}
+	}
+
+	if *watch {
+		return runWatch(flag.Args(), verifyOptions, numCPUs)
+	}
+
+	if *summary != "" && *summary != "text" && *summary != "json" {
+		return &usageError{fmt.Errorf("Unknown summary format: %s", *summary)}
+	}
+
+	stats := impi.Stats{}
+	var verifyErr error
+
 	// TODO: can parallelize across root paths
 	for argIndex := 0; argIndex < flag.NArg(); argIndex++ {
 		rootPath := flag.Arg(argIndex)
@@ -67,30 +95,83 @@ func run() error {
 			return fmt.Errorf("Failed to create impi: %s", err.Error())
 		}
 
-		err = impiInstance.Verify(rootPath, &impi.VerifyOptions{
-			SkipTests:       false,
-			LocalPrefix:     *localPrefix,
-			Scheme:          verificationScheme,
-			SkipPaths:       skipPaths,
-			IgnoreGenerated: *ignoreGenerated,
-		}, &consoleErrorReporter{})
+		verifyErr = impiInstance.Verify(rootPath, verifyOptions, &consoleErrorReporter{})
 
-		if err != nil {
-			return err
+		runStats := impiInstance.GetStats()
+		stats.Add(&runStats)
+
+		if verifyErr != nil {
+			break
 		}
 	}
 
-	return nil
+	if err := printSummary(*summary, &stats); err != nil {
+		return err
+	}
+
+	return getRunError(verifyErr, &stats)
+}
+
+// getRunError returns the error of a run along with its exit code. Failures to verify files take precedence
+// over violations, since the results are incomplete
+func getRunError(err error, stats *impi.Stats) error {
+	if _, ok := err.(*impi.ViolationsError); !ok {
+		return err
+	}
+
+	if stats.NumFailures != 0 {
+		return &exitError{error: err, exitCode: exitCodeFailure}
+	}
+
+	return &exitError{error: err, exitCode: exitCodeViolations}
+}
+
+// getExitCode returns the code to exit with following the error
+func getExitCode(err error) int {
+	switch typedErr := err.(type) {
+	case *exitError:
+		return typedErr.exitCode
+	case *usageError, *impi.ConfigError:
+		return exitCodeUsage
+	default:
+		return exitCodeFailure
+	}
+}
+
+// commands are run as "impi <command> [args]", rather than verifying packages
+var commands = map[string]func(args []string) error{
+	"cache":   runCacheCommand,
+	"explain": runExplainCommand,
+	"hook":    runHookCommand,
+	"infer":   runInferCommand,
+	"migrate": runMigrateCommand,
 }
 
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s PACKAGE [PACKAGE ...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s --watch PACKAGE [PACKAGE ...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s explain --scheme <scheme> FILE [FILE ...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s infer [--output <config>] PACKAGE [PACKAGE ...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s migrate --from <scheme> --to <scheme> PACKAGE [PACKAGE ...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s cache clean\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s hook install|uninstall [--mode check|fix] [--restage]\n", os.Args[0])
 		flag.PrintDefaults()
 	}
 
+	if len(os.Args) > 1 {
+		if command, found := commands[os.Args[1]]; found {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Printf("\nimpi %s failed: %s\n", os.Args[1], err.Error())
+				os.Exit(getExitCode(err))
+			}
+
+			return
+		}
+	}
+
 	if err := run(); err != nil {
 		fmt.Printf("\nimpi verification failed: %s\n", err.Error())
-		os.Exit(1)
+		os.Exit(getExitCode(err))
 	}
 }
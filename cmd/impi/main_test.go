@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/pavius/impi"
+)
+
+type ExitCodeTestSuite struct {
+	suite.Suite
+}
+
+func (s *ExitCodeTestSuite) TestGetExitCode() {
+	s.Require().Equal(exitCodeUsage, getExitCode(&usageError{errors.New("Unknown command")}))
+	s.Require().Equal(exitCodeUsage, getExitCode(&impi.ConfigError{Err: errors.New("Unknown rule: dot-imports")}))
+	s.Require().Equal(exitCodeViolations, getExitCode(&exitError{error: errors.New("violations"), exitCode: exitCodeViolations}))
+	s.Require().Equal(exitCodeFailure, getExitCode(errors.New("open a.go: permission denied")))
+}
+
+func (s *ExitCodeTestSuite) TestGetRunError() {
+	violationsErr := &impi.ViolationsError{NumErrors: 1}
+
+	// violations exit with their own code, unless some files failed to verify
+	s.Require().Equal(exitCodeViolations, getExitCode(getRunError(violationsErr, &impi.Stats{})))
+	s.Require().Equal(exitCodeFailure, getExitCode(getRunError(violationsErr, &impi.Stats{NumFailures: 1})))
+
+	// other errors are returned as they are
+	configErr := &impi.ConfigError{Err: errors.New("Unknown rule: dot-imports")}
+	s.Require().Equal(configErr, getRunError(configErr, &impi.Stats{}))
+	s.Require().Nil(getRunError(nil, &impi.Stats{}))
+}
+
+func (s *ExitCodeTestSuite) TestMissingPackages() {
+	tempDir, err := ioutil.TempDir("", "impi-exit-codes")
+	s.Require().NoError(err)
+
+	defer os.RemoveAll(tempDir)
+
+	verifyOptions := &impi.VerifyOptions{
+		Scheme:      impi.ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}
+
+	// verifying packages which don't exist, or a directory without any, is a usage error
+	for _, rootPath := range []string{path.Join(tempDir, "nothing"), tempDir + "/..."} {
+		impiInstance, err := impi.NewImpi(1)
+		s.Require().NoError(err)
+
+		err = impiInstance.Verify(rootPath, verifyOptions, &consoleErrorReporter{})
+		s.Require().Error(err, rootPath)
+		s.Require().Equal(exitCodeUsage, getExitCode(err), rootPath)
+	}
+}
+
+func TestExitCodeTestSuite(t *testing.T) {
+	suite.Run(t, new(ExitCodeTestSuite))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/pavius/impi"
+)
+
+func runMigrateCommand(args []string) error {
+	flagSet := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s migrate --from <scheme> --to <scheme> [--local <local import prefix>] PACKAGE [PACKAGE ...]\n", os.Args[0])
+		flagSet.PrintDefaults()
+	}
+
+	verifyFlags := newVerifyFlags(flagSet)
+
+	var fromScheme, toScheme impi.ImportGroupVerificationScheme
+	flagSet.Var(&fromScheme, "from", "scheme the files follow. one of stdLocalThirdParty/stdThirdPartyLocal")
+	flagSet.Var(&toScheme, "to", "scheme to migrate the files to. one of stdLocalThirdParty/stdThirdPartyLocal")
+
+	verifyOptions, err := verifyFlags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	if fromScheme == impi.ImportGroupVerificationSchemeSingle || toScheme == impi.ImportGroupVerificationSchemeSingle {
+		flagSet.Usage()
+		return &usageError{errors.New("Both the scheme to migrate from and the scheme to migrate to must be specified")}
+	}
+
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return &usageError{errors.New("No packages to migrate")}
+	}
+
+	verifyOptions.Scheme = toScheme
+
+	stats := impi.Stats{}
+	var migrateErr error
+
+	for _, rootPath := range flagSet.Args() {
+		impiInstance, err := impi.NewImpi(runtime.NumCPU())
+		if err != nil {
+			return fmt.Errorf("Failed to create impi: %s", err.Error())
+		}
+
+		migrateErr = impiInstance.Migrate(rootPath, fromScheme, verifyOptions, &consoleErrorReporter{})
+
+		runStats := impiInstance.GetStats()
+		stats.Add(&runStats)
+
+		if migrateErr != nil {
+			break
+		}
+	}
+
+	fmt.Printf("\nMigrated %d files from %s to %s, %d files could not be migrated\n",
+		stats.NumFilesFixed,
+		fromScheme,
+		toScheme,
+		stats.NumFilesWithViolations)
+
+	return getRunError(migrateErr, &stats)
+}
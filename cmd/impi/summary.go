@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pavius/impi"
+)
+
+// printSummary prints the statistics of the run, either as a text footer or as a JSON block
+func printSummary(format string, stats *impi.Stats) error {
+	switch format {
+	case "":
+		return nil
+
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+
+		return encoder.Encode(stats)
+
+	case "text":
+		numFilesSkipped := 0
+		for _, count := range stats.NumFilesSkipped {
+			numFilesSkipped += count
+		}
+
+		skipReasons := ""
+		if numFilesSkipped != 0 {
+			skipReasons = fmt.Sprintf(" (%s)", formatCounts(stats.NumFilesSkipped))
+		}
+
+		fmt.Printf("\nFiles: %d scanned, %d skipped%s, %d with violations, %d fixed\n",
+			stats.NumFilesScanned,
+			numFilesSkipped,
+			skipReasons,
+			stats.NumFilesWithViolations,
+			stats.NumFilesFixed)
+
+		fmt.Printf("Violations: %s\n", formatCounts(stats.NumViolationsByRule))
+		fmt.Printf("Failures: %d\n", stats.NumFailures)
+		fmt.Printf("Imports: %s\n", formatCounts(stats.NumImportsByClass))
+		fmt.Printf("Elapsed: %s\n", stats.Elapsed.Round(time.Millisecond))
+
+		return nil
+
+	default:
+		return fmt.Errorf("Unknown summary format: %s", format)
+	}
+}
+
+// formatCounts formats counts as "key: count, ..." sorted by key, or "none" if there are none
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+
+	var keys []string
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var formattedCounts []string
+	for _, key := range keys {
+		formattedCounts = append(formattedCounts, fmt.Sprintf("%s: %d", key, counts[key]))
+	}
+
+	return strings.Join(formattedCounts, ", ")
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pavius/impi"
+)
+
+// watchSettleInterval is how long changes must settle before files are verified again, since editors tend
+// to write files in several steps
+const watchSettleInterval = 100 * time.Millisecond
+
+// fileWatcher reports the paths of files and directories that change in the directories it watches
+type fileWatcher interface {
+	addDir(dirPath string) error
+	getChangedPaths() <-chan string
+	getErrors() <-chan error
+}
+
+type collectingErrorReporter struct {
+	verificationErrors []impi.VerificationError
+}
+
+func (cer *collectingErrorReporter) Report(err impi.VerificationError) {
+	cer.verificationErrors = append(cer.verificationErrors, err)
+}
+
+// watchSession holds the current violations of the watched files, updating them as files change
+type watchSession struct {
+	verifyOptions *impi.VerifyOptions
+	numWorkers    int
+	watcher       fileWatcher
+
+	// watched directories, and whether directories created under them are watched as well
+	watchedDirs map[string]bool
+
+	// files watched on their own, rather than as part of a directory
+	watchedFiles map[string]bool
+
+	verificationErrorsByFile map[string][]impi.VerificationError
+	failuresByPath           map[string]error
+	lastVerificationTime     time.Time
+}
+
+// watchChanges are what changed paths call for - paths to forget as they were removed, directories to start
+// watching as they were created and files to verify again
+type watchChanges struct {
+	removedPaths     []string
+	createdDirPaths  []string
+	changedFilePaths []string
+}
+
+// runWatch verifies the root paths, then verifies files again whenever they change until interrupted
+func runWatch(rootPaths []string, verifyOptions *impi.VerifyOptions, numWorkers int) error {
+	watcher, err := newFileWatcher()
+	if err != nil {
+		return err
+	}
+
+	ws := newWatchSession(verifyOptions, numWorkers, watcher)
+
+	for _, rootPath := range rootPaths {
+		if err := ws.watchRootPath(rootPath); err != nil {
+			return err
+		}
+
+		ws.verify(rootPath)
+	}
+
+	ws.render()
+
+	for {
+		changedPaths, err := waitForChangedPaths(watcher, watchSettleInterval)
+		if err != nil {
+			return err
+		}
+
+		if err := ws.handleChangedPaths(changedPaths); err != nil {
+			return err
+		}
+
+		ws.render()
+	}
+}
+
+func newWatchSession(verifyOptions *impi.VerifyOptions, numWorkers int, watcher fileWatcher) *watchSession {
+	return &watchSession{
+		verifyOptions:            verifyOptions,
+		numWorkers:               numWorkers,
+		watcher:                  watcher,
+		watchedDirs:              map[string]bool{},
+		watchedFiles:             map[string]bool{},
+		verificationErrorsByFile: map[string][]impi.VerificationError{},
+		failuresByPath:           map[string]error{},
+	}
+}
+
+// waitForChangedPaths waits for paths to change and returns them once no path changed for the settle interval,
+// so that a file written in several steps is only verified once it's complete
+func waitForChangedPaths(watcher fileWatcher, settleInterval time.Duration) (map[string]bool, error) {
+	changedPaths := map[string]bool{}
+	var settleTimer <-chan time.Time
+
+	for {
+		select {
+		case changedPath := <-watcher.getChangedPaths():
+			changedPaths[filepath.Clean(changedPath)] = true
+			settleTimer = time.After(settleInterval)
+
+		case <-settleTimer:
+			return changedPaths, nil
+
+		case err := <-watcher.getErrors():
+			return nil, err
+		}
+	}
+}
+
+// watchRootPath watches the directories holding the packages of the root path
+func (ws *watchSession) watchRootPath(rootPath string) error {
+	impiInstance, err := impi.NewImpi(ws.numWorkers)
+	if err != nil {
+		return fmt.Errorf("Failed to create impi: %s", err.Error())
+	}
+
+	dirPaths, err := impiInstance.GetDirPaths(rootPath, ws.verifyOptions)
+	if err != nil {
+		return err
+	}
+
+	// a file is watched through its directory, but other files in the directory are not verified
+	if fileInfo, err := os.Stat(rootPath); err == nil && !fileInfo.IsDir() {
+		ws.watchedFiles[filepath.Clean(rootPath)] = true
+
+		return ws.watcher.addDir(dirPaths[0])
+	}
+
+	recursive := rootPath == "..." || strings.HasSuffix(rootPath, "/...")
+
+	for _, dirPath := range dirPaths {
+		if err := ws.watchDir(dirPath, recursive); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ws *watchSession) watchDir(dirPath string, recursive bool) error {
+	if err := ws.watcher.addDir(dirPath); err != nil {
+		return err
+	}
+
+	ws.watchedDirs[filepath.Clean(dirPath)] = recursive
+
+	return nil
+}
+
+// handleChangedPaths verifies changed files, drops the violations of removed ones and starts watching
+// directories created under recursively watched directories
+func (ws *watchSession) handleChangedPaths(changedPaths map[string]bool) error {
+	changes := ws.getWatchChanges(changedPaths)
+
+	for _, removedPath := range changes.removedPaths {
+		ws.forgetPath(removedPath)
+	}
+
+	for _, createdDirPath := range changes.createdDirPaths {
+		if err := ws.handleCreatedDir(createdDirPath); err != nil {
+			return err
+		}
+	}
+
+	for _, changedFilePath := range changes.changedFilePaths {
+		ws.forgetPath(changedFilePath)
+		ws.verify(changedFilePath)
+	}
+
+	return nil
+}
+
+// getWatchChanges tells what the changed paths call for. Only go files that are watched are verified again,
+// and only directories created right under recursively watched directories are watched - those under them are
+// found by walking
+func (ws *watchSession) getWatchChanges(changedPaths map[string]bool) watchChanges {
+	var changes watchChanges
+
+	for changedPath := range changedPaths {
+		fileInfo, err := os.Stat(changedPath)
+
+		switch {
+		case err != nil:
+			changes.removedPaths = append(changes.removedPaths, changedPath)
+
+		case fileInfo.IsDir():
+			if _, watched := ws.watchedDirs[changedPath]; !watched && ws.watchedDirs[filepath.Dir(changedPath)] {
+				changes.createdDirPaths = append(changes.createdDirPaths, changedPath)
+			}
+
+		case strings.HasSuffix(changedPath, ".go") && ws.isWatchedFile(changedPath):
+			changes.changedFilePaths = append(changes.changedFilePaths, changedPath)
+		}
+	}
+
+	sort.Strings(changes.removedPaths)
+	sort.Strings(changes.createdDirPaths)
+	sort.Strings(changes.changedFilePaths)
+
+	return changes
+}
+
+// handleCreatedDir watches a directory created under a recursively watched directory, along with the
+// directories under it, unless they are skipped
+func (ws *watchSession) handleCreatedDir(dirPath string) error {
+	parentDirPath := filepath.Dir(dirPath)
+
+	// walk from the parent so that the new directory itself is subject to skipping
+	impiInstance, err := impi.NewImpi(ws.numWorkers)
+	if err != nil {
+		return fmt.Errorf("Failed to create impi: %s", err.Error())
+	}
+
+	dirPaths, err := impiInstance.GetDirPaths(parentDirPath+"/...", ws.verifyOptions)
+	if err != nil {
+		return err
+	}
+
+	for _, walkedDirPath := range dirPaths {
+		if _, watched := ws.watchedDirs[filepath.Clean(walkedDirPath)]; watched {
+			continue
+		}
+
+		if err := ws.watchDir(walkedDirPath, true); err != nil {
+			return err
+		}
+
+		ws.verify(walkedDirPath)
+	}
+
+	return nil
+}
+
+func (ws *watchSession) isWatchedFile(filePath string) bool {
+	_, dirWatched := ws.watchedDirs[filepath.Dir(filePath)]
+
+	return dirWatched || ws.watchedFiles[filePath]
+}
+
+// forgetPath drops everything known about a path and the paths under it
+func (ws *watchSession) forgetPath(removedPath string) {
+	for filePath := range ws.verificationErrorsByFile {
+		if filePath == removedPath || strings.HasPrefix(filePath, removedPath+string(filepath.Separator)) {
+			delete(ws.verificationErrorsByFile, filePath)
+		}
+	}
+
+	for dirPath := range ws.watchedDirs {
+		if dirPath == removedPath || strings.HasPrefix(dirPath, removedPath+string(filepath.Separator)) {
+			delete(ws.watchedDirs, dirPath)
+		}
+	}
+
+	delete(ws.failuresByPath, removedPath)
+}
+
+// verify verifies a root path, recording the violations of its files
+func (ws *watchSession) verify(rootPath string) {
+	delete(ws.failuresByPath, rootPath)
+
+	impiInstance, err := impi.NewImpi(ws.numWorkers)
+	if err != nil {
+		ws.failuresByPath[rootPath] = fmt.Errorf("Failed to create impi: %s", err.Error())
+		return
+	}
+
+	errorReporter := &collectingErrorReporter{}
+
+	// verification fails whenever violations are found, which isn't a failure of the watch
+	err = impiInstance.Verify(rootPath, ws.verifyOptions, errorReporter)
+	if err != nil && len(errorReporter.verificationErrors) == 0 {
+		ws.failuresByPath[rootPath] = err
+	}
+
+	for _, verificationError := range errorReporter.verificationErrors {
+		filePath := filepath.Clean(verificationError.FilePath)
+		ws.verificationErrorsByFile[filePath] = append(ws.verificationErrorsByFile[filePath], verificationError)
+	}
+
+	ws.lastVerificationTime = time.Now()
+}
+
+// render clears the terminal and prints the current violations
+func (ws *watchSession) render() {
+	fmt.Print("\033[H\033[2J")
+
+	var failedPaths []string
+	for failedPath := range ws.failuresByPath {
+		failedPaths = append(failedPaths, failedPath)
+	}
+
+	sort.Strings(failedPaths)
+
+	for _, failedPath := range failedPaths {
+		fmt.Printf("impi verification of %s failed: %s\n", failedPath, ws.failuresByPath[failedPath].Error())
+	}
+
+	var filePaths []string
+	for filePath := range ws.verificationErrorsByFile {
+		filePaths = append(filePaths, filePath)
+	}
+
+	sort.Strings(filePaths)
+
+	numErrors := 0
+
+	for _, filePath := range filePaths {
+		verificationErrors := ws.verificationErrorsByFile[filePath]
+
+		sort.SliceStable(verificationErrors, func(first, second int) bool {
+			return verificationErrors[first].LineNum < verificationErrors[second].LineNum
+		})
+
+		for _, verificationError := range verificationErrors {
+			fmt.Println(formatVerificationError(verificationError))
+		}
+
+		numErrors += len(verificationErrors)
+	}
+
+	fmt.Printf("\nFound %d errors in %d files, watching %d directories for changes (last verified at %s)\n",
+		numErrors,
+		len(ws.verificationErrorsByFile),
+		len(ws.watchedDirs),
+		ws.lastVerificationTime.Format("15:04:05"))
+}
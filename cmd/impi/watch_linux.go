@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyWatchMask = syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE |
+	syscall.IN_DELETE |
+	syscall.IN_DELETE_SELF |
+	syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO
+
+// inotifyWatcher watches directories with inotify
+type inotifyWatcher struct {
+	fd           int
+	dirPathsLock sync.Mutex
+	dirPaths     map[int32]string
+	changedPaths chan string
+	errors       chan error
+}
+
+func newFileWatcher() (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize inotify: %s", err.Error())
+	}
+
+	iw := &inotifyWatcher{
+		fd:           fd,
+		dirPaths:     map[int32]string{},
+		changedPaths: make(chan string, 1024),
+		errors:       make(chan error, 1),
+	}
+
+	go iw.readEvents()
+
+	return iw, nil
+}
+
+func (iw *inotifyWatcher) addDir(dirPath string) error {
+	watchDescriptor, err := syscall.InotifyAddWatch(iw.fd, dirPath, inotifyWatchMask)
+	if err != nil {
+		return fmt.Errorf("Failed to watch %s: %s", dirPath, err.Error())
+	}
+
+	iw.dirPathsLock.Lock()
+	iw.dirPaths[int32(watchDescriptor)] = dirPath
+	iw.dirPathsLock.Unlock()
+
+	return nil
+}
+
+func (iw *inotifyWatcher) getChangedPaths() <-chan string {
+	return iw.changedPaths
+}
+
+func (iw *inotifyWatcher) getErrors() <-chan error {
+	return iw.errors
+}
+
+func (iw *inotifyWatcher) readEvents() {
+	buffer := make([]byte, 256*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		numBytesRead, err := syscall.Read(iw.fd, buffer)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+
+			iw.errors <- fmt.Errorf("Failed to read inotify events: %s", err.Error())
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= numBytesRead; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+			nameOffset := offset + syscall.SizeofInotifyEvent
+			offset = nameOffset + int(event.Len)
+
+			// events were dropped, so the violations shown can no longer be trusted
+			if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				iw.errors <- errors.New("Too many changes at once, inotify events were dropped")
+				return
+			}
+
+			iw.dirPathsLock.Lock()
+			dirPath, found := iw.dirPaths[event.Wd]
+
+			// the watch is gone once its directory is
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(iw.dirPaths, event.Wd)
+			}
+
+			iw.dirPathsLock.Unlock()
+
+			if !found || event.Mask&syscall.IN_IGNORED != 0 {
+				continue
+			}
+
+			// events of the directory itself have no name
+			name := strings.TrimRight(string(buffer[nameOffset:offset]), "\x00")
+			if name == "" {
+				iw.changedPaths <- dirPath
+				continue
+			}
+
+			iw.changedPaths <- filepath.Join(dirPath, name)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+//go:build !linux
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// watchPollInterval is how often watched directories are listed where there's no inotify
+const watchPollInterval = time.Second
+
+// pollingWatcher watches directories by listing them periodically, comparing modification times
+type pollingWatcher struct {
+	modTimesLock sync.Mutex
+	modTimes     map[string]map[string]time.Time
+	changedPaths chan string
+	errors       chan error
+}
+
+func newFileWatcher() (fileWatcher, error) {
+	pw := &pollingWatcher{
+		modTimes:     map[string]map[string]time.Time{},
+		changedPaths: make(chan string, 1024),
+		errors:       make(chan error, 1),
+	}
+
+	go pw.poll()
+
+	return pw, nil
+}
+
+func (pw *pollingWatcher) addDir(dirPath string) error {
+	modTimes, err := getDirModTimes(dirPath)
+	if err != nil {
+		return err
+	}
+
+	pw.modTimesLock.Lock()
+	pw.modTimes[dirPath] = modTimes
+	pw.modTimesLock.Unlock()
+
+	return nil
+}
+
+func (pw *pollingWatcher) getChangedPaths() <-chan string {
+	return pw.changedPaths
+}
+
+func (pw *pollingWatcher) getErrors() <-chan error {
+	return pw.errors
+}
+
+func (pw *pollingWatcher) poll() {
+	for range time.Tick(watchPollInterval) {
+
+		// changes are sent without holding the lock, since handling them may add directories
+		for _, changedPath := range pw.getChanges() {
+			pw.changedPaths <- changedPath
+		}
+	}
+}
+
+func (pw *pollingWatcher) getChanges() []string {
+	pw.modTimesLock.Lock()
+	defer pw.modTimesLock.Unlock()
+
+	var changedPaths []string
+
+	for dirPath, previousModTimes := range pw.modTimes {
+		modTimes, err := getDirModTimes(dirPath)
+
+		// a directory which can't be listed was removed, and is no longer watched
+		if err != nil {
+			delete(pw.modTimes, dirPath)
+			changedPaths = append(changedPaths, dirPath)
+
+			continue
+		}
+
+		for name, modTime := range modTimes {
+			if previousModTime, found := previousModTimes[name]; !found || !modTime.Equal(previousModTime) {
+				changedPaths = append(changedPaths, filepath.Join(dirPath, name))
+			}
+		}
+
+		for name := range previousModTimes {
+			if _, found := modTimes[name]; !found {
+				changedPaths = append(changedPaths, filepath.Join(dirPath, name))
+			}
+		}
+
+		pw.modTimes[dirPath] = modTimes
+	}
+
+	return changedPaths
+}
+
+func getDirModTimes(dirPath string) (map[string]time.Time, error) {
+	fileInfos, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	modTimes := map[string]time.Time{}
+
+	for _, fileInfo := range fileInfos {
+		modTimes[fileInfo.Name()] = fileInfo.ModTime()
+	}
+
+	return modTimes, nil
+}
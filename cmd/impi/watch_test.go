@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/pavius/impi"
+)
+
+// fakeFileWatcher reports the changes and errors sent to it, and records the directories it watches
+type fakeFileWatcher struct {
+	dirPaths     []string
+	changedPaths chan string
+	errors       chan error
+}
+
+func newFakeFileWatcher() *fakeFileWatcher {
+	return &fakeFileWatcher{
+		changedPaths: make(chan string, 16),
+		errors:       make(chan error, 1),
+	}
+}
+
+func (ffw *fakeFileWatcher) addDir(dirPath string) error {
+	ffw.dirPaths = append(ffw.dirPaths, dirPath)
+	return nil
+}
+
+func (ffw *fakeFileWatcher) getChangedPaths() <-chan string {
+	return ffw.changedPaths
+}
+
+func (ffw *fakeFileWatcher) getErrors() <-chan error {
+	return ffw.errors
+}
+
+type WatchTestSuite struct {
+	suite.Suite
+	tempDir      string
+	watcher      *fakeFileWatcher
+	watchSession *watchSession
+}
+
+func (s *WatchTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-watch")
+	s.Require().NoError(err)
+
+	s.watcher = newFakeFileWatcher()
+	s.watchSession = newWatchSession(&impi.VerifyOptions{
+		Scheme:      impi.ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}, 1, s.watcher)
+}
+
+func (s *WatchTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *WatchTestSuite) TestWaitForChangedPaths() {
+
+	// changes keep coming in while the file is written, and are collected until they settle
+	go func() {
+		s.watcher.changedPaths <- path.Join(s.tempDir, "a.go")
+		time.Sleep(10 * time.Millisecond)
+		s.watcher.changedPaths <- path.Join(s.tempDir, ".", "a.go")
+		s.watcher.changedPaths <- path.Join(s.tempDir, "b.go")
+	}()
+
+	changedPaths, err := waitForChangedPaths(s.watcher, 200*time.Millisecond)
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]bool{
+		path.Join(s.tempDir, "a.go"): true,
+		path.Join(s.tempDir, "b.go"): true,
+	}, changedPaths)
+
+	// failures of the watcher end the wait
+	s.watcher.errors <- errors.New("Too many changes at once")
+
+	_, err = waitForChangedPaths(s.watcher, 200*time.Millisecond)
+	s.Require().EqualError(err, "Too many changes at once")
+}
+
+func (s *WatchTestSuite) TestGetWatchChanges() {
+	for _, filePath := range []string{"a.go", "README.md", "new/b.go", "flat/c.go", "flat/sub/d.go", "flat/sub/e.go"} {
+		s.writeFile(filePath, "package fixtures\n")
+	}
+
+	s.watchSession.watchedDirs[s.tempDir] = true
+	s.watchSession.watchedDirs[path.Join(s.tempDir, "flat")] = false
+	s.watchSession.watchedFiles[path.Join(s.tempDir, "flat", "sub", "e.go")] = true
+
+	changes := s.watchSession.getWatchChanges(s.getPaths("a.go", "README.md", "gone.go", "new", "flat", "flat/c.go", "flat/sub", "flat/sub/d.go", "flat/sub/e.go"))
+
+	// only directories created under recursively watched directories are watched, and only watched
+	// go files are verified
+	s.Require().Equal(watchChanges{
+		removedPaths:     []string{path.Join(s.tempDir, "gone.go")},
+		createdDirPaths:  []string{path.Join(s.tempDir, "new")},
+		changedFilePaths: s.getSortedPaths("a.go", "flat/c.go", "flat/sub/e.go"),
+	}, changes)
+}
+
+func (s *WatchTestSuite) TestHandleChangedPaths() {
+	misorderedContents := "package fixtures\n\nimport (\n\t\"github.com/some/thirdparty\"\n\t\"fmt\"\n)\n"
+	orderedContents := "package fixtures\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/some/thirdparty\"\n)\n"
+
+	s.writeFile("a.go", misorderedContents)
+	s.writeFile("b.go", misorderedContents)
+
+	s.Require().NoError(s.watchSession.watchRootPath(s.tempDir + "/..."))
+	s.watchSession.verify(s.tempDir + "/...")
+	s.Require().Equal(s.getSortedPaths("a.go", "b.go"), s.getViolatingFilePaths())
+
+	// only changed files are verified again
+	s.writeFile("a.go", orderedContents)
+	s.writeFile("b.go", orderedContents)
+
+	s.Require().NoError(s.watchSession.handleChangedPaths(s.getPaths("a.go")))
+	s.Require().Equal(s.getSortedPaths("b.go"), s.getViolatingFilePaths())
+
+	// created directories are watched along with those under them, unless they're skipped, and verified
+	s.writeFile("new/c.go", misorderedContents)
+	s.writeFile("new/sub/d.go", misorderedContents)
+	s.writeFile("new/testdata/e.go", misorderedContents)
+
+	s.Require().NoError(s.watchSession.handleChangedPaths(s.getPaths("new")))
+	s.Require().Equal([]string{s.tempDir, path.Join(s.tempDir, "new"), path.Join(s.tempDir, "new", "sub")}, s.watcher.dirPaths)
+	s.Require().Equal(s.getSortedPaths("b.go", "new/c.go", "new/sub/d.go"), s.getViolatingFilePaths())
+
+	// removed files and directories are forgotten
+	s.Require().NoError(os.Remove(path.Join(s.tempDir, "b.go")))
+	s.Require().NoError(os.RemoveAll(path.Join(s.tempDir, "new")))
+
+	s.Require().NoError(s.watchSession.handleChangedPaths(s.getPaths("b.go", "new")))
+	s.Require().Empty(s.getViolatingFilePaths())
+	s.Require().Equal(map[string]bool{s.tempDir: true}, s.watchSession.watchedDirs)
+}
+
+func (s *WatchTestSuite) writeFile(relPath string, contents string) {
+	filePath := path.Join(s.tempDir, relPath)
+
+	s.Require().NoError(os.MkdirAll(path.Dir(filePath), 0755))
+	s.Require().NoError(ioutil.WriteFile(filePath, []byte(contents), 0644))
+}
+
+func (s *WatchTestSuite) getPaths(relPaths ...string) map[string]bool {
+	paths := map[string]bool{}
+
+	for _, relPath := range relPaths {
+		paths[path.Join(s.tempDir, relPath)] = true
+	}
+
+	return paths
+}
+
+func (s *WatchTestSuite) getSortedPaths(relPaths ...string) []string {
+	var sortedPaths []string
+
+	for sortedPath := range s.getPaths(relPaths...) {
+		sortedPaths = append(sortedPaths, sortedPath)
+	}
+
+	sort.Strings(sortedPaths)
+
+	return sortedPaths
+}
+
+func (s *WatchTestSuite) getViolatingFilePaths() []string {
+	var filePaths []string
+
+	for filePath := range s.watchSession.verificationErrorsByFile {
+		filePaths = append(filePaths, filePath)
+	}
+
+	sort.Strings(filePaths)
+
+	return filePaths
+}
+
+func TestWatchTestSuite(t *testing.T) {
+	suite.Run(t, new(WatchTestSuite))
+}
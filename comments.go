@@ -0,0 +1,67 @@
+package impi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// verifyImportComments verifies that groups carry the header comments the options require, and that
+// imports don't carry trailing comments if the options forbid them
+func (v *verifier) verifyImportComments(importInfoGroups []importInfoGroup) (ruleViolations, error) {
+	requiredGroupHeaderTypes, err := getRequiredGroupHeaderTypes(v.verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations ruleViolations
+
+	for _, importInfoGroup := range importInfoGroups {
+		if len(importInfoGroup.importInfos) == 0 {
+			continue
+		}
+
+		firstImportInfo := importInfoGroup.importInfos[0]
+
+		if requiredGroupHeaderTypes[firstImportInfo.classifiedType] && !importInfoGroup.hasHeaderComment {
+			violations = append(violations, &ruleViolation{
+				rule:    ruleGroupHeader,
+				lineNum: firstImportInfo.lineNum,
+				message: fmt.Sprintf("Groups of %s imports must start with a header comment: %s",
+					importTypeStatsName[firstImportInfo.classifiedType],
+					strings.TrimSpace(firstImportInfo.lineValue)),
+			})
+		}
+
+		if !v.verifyOptions.ForbidTrailingComments {
+			continue
+		}
+
+		for _, importInfo := range importInfoGroup.importInfos {
+			if importInfo.hasTrailingComment {
+				violations = append(violations, &ruleViolation{
+					rule:    ruleTrailingComment,
+					lineNum: importInfo.lineNum,
+					message: fmt.Sprintf("Imports must not carry trailing comments: %s", strings.TrimSpace(importInfo.lineValue)),
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// getRequiredGroupHeaderTypes returns the types of imports whose groups must start with a header comment
+func getRequiredGroupHeaderTypes(verifyOptions *VerifyOptions) (map[importType]bool, error) {
+	requiredGroupHeaderTypes := map[importType]bool{}
+
+	for _, class := range verifyOptions.RequireGroupHeaders {
+		classifiedType := findImportTypeByStatsName(class)
+		if classifiedType == importTypeUnknown {
+			return nil, fmt.Errorf("Unknown class of imports in required group headers: %q", class)
+		}
+
+		requiredGroupHeaderTypes[classifiedType] = true
+	}
+
+	return requiredGroupHeaderTypes, nil
+}
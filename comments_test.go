@@ -0,0 +1,156 @@
+package impi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CommentsTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *CommentsTestSuite) SetupTest() {
+	s.VerifierTestSuite.SetupTest()
+
+	s.options = VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}
+}
+
+func (s *CommentsTestSuite) TestHeaderSeparatedByEmptyLine() {
+	s.Require().NoError(s.verify(`package fixtures
+
+import (
+	"fmt"
+
+	// third party
+
+	"github.com/other/bar"
+)
+`))
+}
+
+func (s *CommentsTestSuite) TestCommentGroupBoundaries() {
+	contents := `package fixtures
+
+import (
+	"fmt"
+	// third party
+	"github.com/other/bar"
+)
+`
+
+	err := s.verify(contents)
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "Imports of different types are not allowed in the same group")
+
+	s.options.CommentGroupBoundaries = true
+	s.Require().NoError(s.verify(contents))
+}
+
+func (s *CommentsTestSuite) TestRequireGroupHeaders() {
+	s.options.RequireGroupHeaders = []string{"third-party"}
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Headed",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	// third party
+	"github.com/other/bar"
+)
+`,
+		},
+		{
+			name: "Not headed",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/other/bar"
+)
+`,
+			expectedErrorStrings: []string{
+				"Groups of third-party imports must start with a header comment: \"github.com/other/bar\"",
+			},
+		},
+		{
+			name: "Comment not heading the group",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/other/bar"
+	// baz does things
+	"github.com/other/baz"
+)
+`,
+			expectedErrorStrings: []string{
+				"Groups of third-party imports must start with a header comment: \"github.com/other/bar\"",
+			},
+		},
+	}
+
+	s.verifyTestCases(verificationTestCases)
+}
+
+func (s *CommentsTestSuite) TestForbidTrailingComments() {
+	s.options.ForbidTrailingComments = true
+
+	err := s.verify(`package fixtures
+
+import (
+	// doc comments are allowed
+	"fmt"
+	"os" // trailing comments are not
+)
+`)
+	s.Require().Error(err)
+
+	violations := err.(ruleViolations)
+	s.Require().Len(violations, 1)
+	s.Require().Equal(ruleTrailingComment, violations[0].rule)
+	s.Require().Equal(6, violations[0].lineNum)
+}
+
+func (s *CommentsTestSuite) TestFixKeepsHeaders() {
+	s.options.RequireGroupHeaders = []string{"third-party"}
+
+	fixer, err := newFixer()
+	s.Require().NoError(err)
+
+	fixedContents, err := fixer.fix("fixtures.go", []byte(`package fixtures
+
+import (
+	// third party
+	"github.com/other/baz" // baz
+	"fmt"
+	// bar does things
+	"github.com/other/bar"
+)
+`), &s.options)
+	s.Require().NoError(err)
+
+	s.Require().Equal(`package fixtures
+
+import (
+	"fmt"
+
+	// third party
+	// bar does things
+	"github.com/other/bar"
+	"github.com/other/baz" // baz
+)
+`, string(fixedContents))
+}
+
+func TestCommentsTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentsTestSuite))
+}
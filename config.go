@@ -0,0 +1,60 @@
+package impi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// DefaultConfigFileName is the name of the configuration file read from the working directory when no other is
+// given. Files of the same name in directories under it are nested configuration files
+const DefaultConfigFileName = ".impi.json"
+
+// ReadVerifyOptionsFile reads verification options from a JSON configuration file. Options that the
+// file does not specify are left as they are. Failures to read or parse the file are configuration errors
+func ReadVerifyOptionsFile(filePath string, verifyOptions *VerifyOptions) error {
+	contents, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	if err := decodeVerifyOptions(contents, verifyOptions); err != nil {
+		return &ConfigError{Err: fmt.Errorf("Failed to parse configuration file %s: %s", filePath, err.Error())}
+	}
+
+	return nil
+}
+
+// decodeVerifyOptions decodes JSON encoded options over the options. Options that the JSON does not specify
+// are left as they are
+func decodeVerifyOptions(contents []byte, verifyOptions *VerifyOptions) error {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+
+	// catch typos in option names rather than silently ignoring them
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(verifyOptions)
+}
+
+// EncodeVerifyOptions encodes verification options as the contents of a JSON configuration file, omitting
+// options which aren't set
+func EncodeVerifyOptions(verifyOptions *VerifyOptions) ([]byte, error) {
+	contents, err := json.MarshalIndent(verifyOptions, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(contents, '\n'), nil
+}
+
+// WriteVerifyOptionsFile writes verification options to a JSON configuration file, omitting options which
+// aren't set
+func WriteVerifyOptionsFile(filePath string, verifyOptions *VerifyOptions) error {
+	contents, err := EncodeVerifyOptions(verifyOptions)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filePath, contents, 0644)
+}
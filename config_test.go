@@ -0,0 +1,111 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (s *ConfigTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-config")
+	s.Require().NoError(err)
+}
+
+func (s *ConfigTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *ConfigTestSuite) writeConfig(contents string) string {
+	configPath := path.Join(s.tempDir, ".impi.json")
+	s.Require().NoError(ioutil.WriteFile(configPath, []byte(contents), 0644))
+
+	return configPath
+}
+
+func (s *ConfigTestSuite) TestReadVerifyOptionsFile() {
+	configPath := s.writeConfig(`{
+    "scheme": "stdThirdPartyLocal",
+    "local": "github.com/pavius/impi",
+    "canonical-aliases": {
+        "k8s.io/api/core/v1": "corev1"
+    }
+}`)
+
+	verifyOptions := VerifyOptions{
+		IgnoreGenerated: true,
+	}
+
+	s.Require().NoError(ReadVerifyOptionsFile(configPath, &verifyOptions))
+	s.Require().Equal(ImportGroupVerificationSchemeStdThirdPartyLocal, verifyOptions.Scheme)
+	s.Require().Equal("github.com/pavius/impi", verifyOptions.LocalPrefix)
+	s.Require().Equal(map[string]string{"k8s.io/api/core/v1": "corev1"}, verifyOptions.CanonicalAliases)
+
+	// options not in the file are left as they are
+	s.Require().True(verifyOptions.IgnoreGenerated)
+}
+
+func (s *ConfigTestSuite) TestReadVerifyOptionsFileInvalid() {
+	for _, contents := range []string{
+		`{"scheme": "noSuchScheme"}`,
+		`{"no-such-option": true}`,
+	} {
+		err := ReadVerifyOptionsFile(s.writeConfig(contents), &VerifyOptions{})
+		s.Require().IsType(&ConfigError{}, err, contents)
+	}
+}
+
+func (s *ConfigTestSuite) TestConfigErrors() {
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	verifyOptions := VerifyOptions{
+		Scheme:    ImportGroupVerificationSchemeStdLocalThirdParty,
+		SkipPaths: []string{"("},
+	}
+
+	// invalid options are configuration errors
+	err = impi.Verify(s.tempDir, &verifyOptions, &collectingErrorReporter{})
+	s.Require().IsType(&ConfigError{}, err)
+
+	// so are packages to verify which don't exist
+	verifyOptions.SkipPaths = nil
+
+	err = impi.Verify(path.Join(s.tempDir, "nonexistent", "..."), &verifyOptions, &collectingErrorReporter{})
+	s.Require().IsType(&ConfigError{}, err)
+}
+
+func (s *ConfigTestSuite) TestWriteVerifyOptionsFile() {
+	configPath := path.Join(s.tempDir, ".impi.json")
+
+	s.Require().NoError(WriteVerifyOptionsFile(configPath, &VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}))
+
+	contents, err := ioutil.ReadFile(configPath)
+	s.Require().NoError(err)
+	s.Require().Equal(`{
+    "scheme": "stdLocalThirdParty",
+    "local": "github.com/pavius/impi"
+}
+`, string(contents))
+
+	var verifyOptions VerifyOptions
+
+	s.Require().NoError(ReadVerifyOptionsFile(configPath, &verifyOptions))
+	s.Require().Equal(ImportGroupVerificationSchemeStdLocalThirdParty, verifyOptions.Scheme)
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
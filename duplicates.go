@@ -0,0 +1,260 @@
+package impi
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// fileImport is an import of a file, as verified against the imports of the other files of its package
+type fileImport struct {
+	path    string
+	name    string
+	lineNum int
+}
+
+// packageImportName is the name under which a file of a package imports a path
+type packageImportName struct {
+	filePath string
+	lineNum  int
+}
+
+// verifyDuplicateImports verifies that no path is imported more than once - neither under different names
+// nor once blank and once named
+func (v *verifier) verifyDuplicateImports(importInfoGroups []importInfoGroup) ruleViolations {
+	var violations ruleViolations
+
+	importInfosByPath := map[string]*importInfo{}
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			firstImportInfo, found := importInfosByPath[importInfo.path]
+			if !found {
+				importInfosByPath[importInfo.path] = importInfo
+				continue
+			}
+
+			var message string
+
+			switch {
+			case importInfo.name == firstImportInfo.name:
+				message = "Import is duplicated"
+			case importInfo.name == "_" || firstImportInfo.name == "_":
+				message = "Import is both blank and named"
+			default:
+				message = "Import is imported under different names"
+			}
+
+			violations = append(violations, &ruleViolation{
+				rule:    ruleDuplicateImport,
+				lineNum: importInfo.lineNum,
+				message: fmt.Sprintf("%s (first imported on line %d): %s",
+					message,
+					firstImportInfo.lineNum,
+					strings.TrimSpace(importInfo.lineValue)),
+			})
+		}
+	}
+
+	return violations
+}
+
+// getFileImports returns the imports whose names are verified across the files of the package - those
+// which aren't blank or dot imports
+func getFileImports(importInfoGroups []importInfoGroup) []fileImport {
+	var fileImports []fileImport
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			if importInfo.name == "_" || importInfo.name == "." {
+				continue
+			}
+
+			fileImports = append(fileImports, fileImport{
+				path:    importInfo.path,
+				name:    importInfo.name,
+				lineNum: importInfo.lineNum,
+			})
+		}
+	}
+
+	return fileImports
+}
+
+// getInconsistentAliasErrors returns the errors of imports whose name differs from the name most files of the
+// package import the path under. Imports without an alias are named by the default package name. The files of a
+// package are those of a directory which declare the same package name, so external test packages are
+// verified on their own
+func (i *Impi) getInconsistentAliasErrors() []VerificationError {
+	i.statsLock.Lock()
+	defer i.statsLock.Unlock()
+
+	// names by package, then by import path
+	importNamesByPackage := map[string]map[string]map[string][]packageImportName{}
+
+	for filePath, packageFile := range i.packageFiles {
+		packageKey := filepath.Dir(filePath) + "\x00" + packageFile.packageName
+
+		if importNamesByPackage[packageKey] == nil {
+			importNamesByPackage[packageKey] = map[string]map[string][]packageImportName{}
+		}
+
+		for _, fileImport := range packageFile.imports {
+			name := fileImport.name
+			if name == "" {
+				name = getDefaultPackageName(fileImport.path)
+			}
+
+			if importNamesByPackage[packageKey][fileImport.path] == nil {
+				importNamesByPackage[packageKey][fileImport.path] = map[string][]packageImportName{}
+			}
+
+			importNamesByPackage[packageKey][fileImport.path][name] = append(importNamesByPackage[packageKey][fileImport.path][name],
+				packageImportName{filePath: filePath, lineNum: fileImport.lineNum})
+		}
+	}
+
+	var verificationErrors []VerificationError
+
+	for _, importNamesByPath := range importNamesByPackage {
+		for importPath, importNames := range importNamesByPath {
+			if len(importNames) < 2 {
+				continue
+			}
+
+			commonName := getCommonImportName(importNames)
+
+			for name, packageImportNames := range importNames {
+				if name == commonName {
+					continue
+				}
+
+				for _, packageImportName := range packageImportNames {
+
+					// the options of the file were resolved when it was verified, failing it if they're invalid
+					verifyOptions, err := i.optionsResolver.resolve(packageImportName.filePath)
+					if err != nil {
+						continue
+					}
+
+					severity := verifyOptions.getSeverity(ruleInconsistentAlias)
+					if severity == SeverityOff {
+						continue
+					}
+
+					verificationErrors = append(verificationErrors, VerificationError{
+						error: fmt.Errorf("%s is imported as %s, but as %s in %d other files of the package",
+							importPath,
+							name,
+							commonName,
+							len(importNames[commonName])),
+						FilePath:   packageImportName.filePath,
+						Kind:       FindingKindViolation,
+						Rule:       ruleInconsistentAlias,
+						LineNum:    packageImportName.lineNum,
+						ReportOnly: i.packageFiles[packageImportName.filePath].reportOnly,
+						Severity:   severity,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(verificationErrors, func(first, second int) bool {
+		if verificationErrors[first].FilePath != verificationErrors[second].FilePath {
+			return verificationErrors[first].FilePath < verificationErrors[second].FilePath
+		}
+
+		return verificationErrors[first].LineNum < verificationErrors[second].LineNum
+	})
+
+	return verificationErrors
+}
+
+// getCommonImportName returns the name most files import a path under, the first in lexical order if there
+// are several
+func getCommonImportName(importNames map[string][]packageImportName) string {
+	commonName := ""
+
+	for name, packageImportNames := range importNames {
+		numCommonNameImports := len(importNames[commonName])
+
+		if commonName == "" ||
+			len(packageImportNames) > numCommonNameImports ||
+			(len(packageImportNames) == numCommonNameImports && name < commonName) {
+			commonName = name
+		}
+	}
+
+	return commonName
+}
+
+// getDefaultPackageName returns the name a package is assumed to have given its import path, much like goimports
+// assumes it - the last element of the path, skipping major versions (e.g. github.com/foo/bar/v2), without a
+// "go-" prefix and up to the first character which isn't valid in identifiers (e.g. gopkg.in/yaml.v3)
+func getDefaultPackageName(importPath string) string {
+	pathElements := strings.Split(importPath, "/")
+	defaultPackageName := pathElements[len(pathElements)-1]
+
+	// major versions start at 2 - packages may be named v1 (e.g. k8s.io/api/core/v1)
+	if majorVersionRegex.MatchString(defaultPackageName) &&
+		defaultPackageName != "v0" &&
+		defaultPackageName != "v1" &&
+		len(pathElements) > 1 {
+		defaultPackageName = pathElements[len(pathElements)-2]
+	}
+
+	defaultPackageName = strings.TrimPrefix(defaultPackageName, "go-")
+
+	if nonIdentifierIndex := strings.IndexFunc(defaultPackageName, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); nonIdentifierIndex != -1 {
+		defaultPackageName = defaultPackageName[:nonIdentifierIndex]
+	}
+
+	return defaultPackageName
+}
+
+// collapseDuplicateImports removes imports of paths which are already imported under the same name, and blank
+// imports of paths which are imported under a name. The comments of the removed imports are kept with the
+// import that remains
+func collapseDuplicateImports(fixedImports []*fixedImport) []*fixedImport {
+	var collapsedFixedImports []*fixedImport
+
+	for _, candidateImport := range fixedImports {
+		collapsed := false
+
+		for collapsedImportIndex, collapsedImport := range collapsedFixedImports {
+			if candidateImport.path != collapsedImport.path {
+				continue
+			}
+
+			// a named import replaces a blank import of the same path
+			keptImport, removedImport := collapsedImport, candidateImport
+			if collapsedImport.name == "_" && candidateImport.name != "_" {
+				keptImport, removedImport = candidateImport, collapsedImport
+			}
+
+			if keptImport.name != removedImport.name && removedImport.name != "_" {
+				continue
+			}
+
+			keptImport.headerComments = append(keptImport.headerComments, removedImport.headerComments...)
+			keptImport.docComments = append(keptImport.docComments, removedImport.docComments...)
+			keptImport.lineComments = append(keptImport.lineComments, removedImport.lineComments...)
+
+			collapsedFixedImports[collapsedImportIndex] = keptImport
+			collapsed = true
+
+			break
+		}
+
+		if !collapsed {
+			collapsedFixedImports = append(collapsedFixedImports, candidateImport)
+		}
+	}
+
+	return collapsedFixedImports
+}
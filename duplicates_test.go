@@ -0,0 +1,235 @@
+package impi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DuplicatesTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *DuplicatesTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.ForbidDuplicateImports = true
+}
+
+func (s *DuplicatesTestSuite) TestVerify() {
+	err := s.verify(`package fixtures
+
+import (
+	"fmt"
+	format "fmt"
+	"os"
+	"os"
+
+	_ "github.com/other/bar"
+	"github.com/other/bar"
+)
+`)
+	s.Require().Error(err)
+
+	violations := err.(ruleViolations)
+	s.Require().Len(violations, 3)
+
+	for violationIndex, expectedViolation := range []struct {
+		lineNum int
+		message string
+	}{
+		{5, `Import is imported under different names (first imported on line 4): format "fmt"`},
+		{7, `Import is duplicated (first imported on line 6): "os"`},
+		{10, `Import is both blank and named (first imported on line 9): "github.com/other/bar"`},
+	} {
+		s.Require().Equal(ruleDuplicateImport, violations[violationIndex].rule)
+		s.Require().Equal(expectedViolation.lineNum, violations[violationIndex].lineNum)
+		s.Require().Equal(expectedViolation.message, violations[violationIndex].message)
+	}
+}
+
+func (s *DuplicatesTestSuite) TestFix() {
+	fixer, err := newFixer()
+	s.Require().NoError(err)
+
+	fixedContents, err := fixer.fix("fixtures.go", []byte(`package fixtures
+
+import (
+	"fmt"
+	format "fmt"
+	"os" // first
+	"os" // second
+
+	// register bar
+	_ "github.com/other/bar"
+	"github.com/other/bar"
+)
+`), &s.options)
+	s.Require().NoError(err)
+
+	// imports under different names can't be collapsed without renaming their uses
+	s.Require().Equal(`package fixtures
+
+import (
+	"fmt"
+	format "fmt"
+	"os" // first // second
+
+	// register bar
+	"github.com/other/bar"
+)
+`, string(fixedContents))
+}
+
+func (s *DuplicatesTestSuite) TestGetDefaultPackageName() {
+	for importPath, expectedPackageName := range map[string]string{
+		"fmt":                     "fmt",
+		"net/http":                "http",
+		"github.com/foo/bar/v2":   "bar",
+		"github.com/foo/go-bar":   "bar",
+		"gopkg.in/yaml.v3":        "yaml",
+		"github.com/foo/bar-baz":  "bar",
+		"github.com/foo/bar_baz":  "bar_baz",
+		"k8s.io/api/core/v1":      "v1",
+		"github.com/foo/bar.go/x": "x",
+	} {
+		s.Require().Equal(expectedPackageName, getDefaultPackageName(importPath), importPath)
+	}
+}
+
+func (s *DuplicatesTestSuite) TestRequireConsistentAliases() {
+	tempDir, err := ioutil.TempDir("", "impi-duplicates")
+	s.Require().NoError(err)
+
+	defer os.RemoveAll(tempDir)
+
+	for filePath, contents := range map[string]string{
+		"a.go": `package fixtures
+
+import (
+	errs "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+`,
+		"b.go": `package fixtures
+
+import (
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
+)
+`,
+		"c.go": `package fixtures
+
+import (
+	"github.com/pkg/errors"
+)
+`,
+		"other/d.go": `package other
+
+import (
+	errs "github.com/pkg/errors"
+)
+`,
+	} {
+		s.Require().NoError(os.MkdirAll(path.Dir(path.Join(tempDir, filePath)), 0755))
+		s.Require().NoError(ioutil.WriteFile(path.Join(tempDir, filePath), []byte(contents), 0644))
+	}
+
+	impi, err := NewImpi(2)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+
+	err = impi.Verify(path.Join(tempDir, "..."), &VerifyOptions{
+		Scheme:                   ImportGroupVerificationSchemeStdThirdPartyLocal,
+		LocalPrefix:              "github.com/pavius/impi",
+		RequireConsistentAliases: true,
+	}, errorReporter)
+	s.Require().Error(err)
+
+	s.Require().Len(errorReporter.verificationErrors, 1)
+	s.Require().Equal(path.Join(tempDir, "a.go"), errorReporter.verificationErrors[0].FilePath)
+	s.Require().Equal(4, errorReporter.verificationErrors[0].LineNum)
+	s.Require().Equal(ruleInconsistentAlias, errorReporter.verificationErrors[0].Rule)
+	s.Require().EqualError(errorReporter.verificationErrors[0],
+		"github.com/pkg/errors is imported as errs, but as errors in 2 other files of the package")
+	s.Require().Equal(1, impi.GetStats().NumViolationsByRule[ruleInconsistentAlias])
+}
+
+func (s *DuplicatesTestSuite) TestRequireConsistentAliasesByPackage() {
+	tempDir, err := ioutil.TempDir("", "impi-duplicates")
+	s.Require().NoError(err)
+
+	defer os.RemoveAll(tempDir)
+
+	for filePath, contents := range map[string]string{
+		"a.go": `package fixtures
+
+import (
+	"github.com/pkg/errors"
+)
+`,
+		"b.go": `package fixtures
+
+import (
+	"github.com/pkg/errors"
+)
+`,
+		"c.go": `package fixtures
+
+import (
+	errs "github.com/pkg/errors"
+)
+`,
+		"c_test.go": `package fixtures
+
+import (
+	errs "github.com/pkg/errors"
+)
+`,
+		"d_test.go": `package fixtures_test
+
+import (
+	errs "github.com/pkg/errors"
+)
+`,
+	} {
+		s.Require().NoError(ioutil.WriteFile(path.Join(tempDir, filePath), []byte(contents), 0644))
+	}
+
+	impi, err := NewImpi(2)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+
+	err = impi.Verify(path.Join(tempDir, "..."), &VerifyOptions{
+		Scheme:                   ImportGroupVerificationSchemeStdThirdPartyLocal,
+		LocalPrefix:              "github.com/pavius/impi",
+		RequireConsistentAliases: true,
+		Tests:                    TestFilesPolicyReportOnly,
+		Overrides: []VerifyOptionsOverride{
+			{
+				Paths:   []string{"/c\\.go$"},
+				Options: json.RawMessage(`{"severities": {"inconsistent-alias": "warning"}}`),
+			},
+		},
+	}, errorReporter)
+	s.Require().NoError(err)
+
+	// the external test package is verified on its own, and findings follow the options of their files
+	s.Require().Len(errorReporter.verificationErrors, 2)
+	s.Require().Equal(path.Join(tempDir, "c.go"), errorReporter.verificationErrors[0].FilePath)
+	s.Require().Equal(SeverityWarning, errorReporter.verificationErrors[0].Severity)
+	s.Require().False(errorReporter.verificationErrors[0].ReportOnly)
+	s.Require().Equal(path.Join(tempDir, "c_test.go"), errorReporter.verificationErrors[1].FilePath)
+	s.Require().Equal(SeverityError, errorReporter.verificationErrors[1].Severity)
+	s.Require().True(errorReporter.verificationErrors[1].ReportOnly)
+}
+
+func TestDuplicatesTestSuite(t *testing.T) {
+	suite.Run(t, new(DuplicatesTestSuite))
+}
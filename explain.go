@@ -0,0 +1,171 @@
+package impi
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// ImportExplanation tells how an import was classified, which group it resides in and where the scheme
+// expects it
+type ImportExplanation struct {
+	LineNum int
+	Path    string
+	Name    string
+
+	// GroupIndex is the index of the group in which the import resides
+	GroupIndex int
+
+	// Type is the type the import was classified as, and Reason is why
+	Type   string
+	Reason string
+
+	// ExpectedGroupIndex and ExpectedIndexInGroup are where the scheme expects the import, given the
+	// other imports of the file
+	ExpectedGroupIndex   int
+	ExpectedIndexInGroup int
+}
+
+// importPosition is the position of an import within the groups of a file
+type importPosition struct {
+	groupIndex   int
+	indexInGroup int
+}
+
+// Explain returns the explanations of the imports of a file, in the order in which they're declared
+func Explain(filePath string, verifyOptions *VerifyOptions) ([]ImportExplanation, error) {
+	source, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	optionsResolver, err := newOptionsResolver(verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// explain the file as it's verified, overrides included
+	verifyOptions, err = optionsResolver.resolve(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := newVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	return verifier.explain(filePath, bytes.NewReader(source), verifyOptions)
+}
+
+func (v *verifier) explain(filePath string,
+	sourceFileReader io.ReadSeeker,
+	verifyOptions *VerifyOptions) ([]ImportExplanation, error) {
+	v.verifyOptions = verifyOptions
+	v.filePath = filePath
+
+	// group and classify the imports the way verification does
+	importLineNumbers, err := v.getImportPos(sourceFileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(importLineNumbers) == 0 {
+		return nil, nil
+	}
+
+	importInfos, err := v.readImportInfos(importLineNumbers[0],
+		importLineNumbers[len(importLineNumbers)-1],
+		sourceFileReader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	importInfoGroups := v.groupImportInfos(importInfos, importLineNumbers)
+	v.classifyImportTypes(importInfoGroups)
+
+	packageUnderTestPath, err := getPackageUnderTestPath(filePath, v.packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedImportPositions, err := getExpectedImportPositions(&fixer{
+		verifyOptions:        verifyOptions,
+		filePath:             filePath,
+		packageUnderTestPath: packageUnderTestPath,
+	}, importInfoGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	var importExplanations []ImportExplanation
+	groupIndex := 0
+
+	for _, importInfoGroup := range importInfoGroups {
+
+		// groups holding no imports (e.g. only comments) aren't counted
+		if len(importInfoGroup.importInfos) == 0 {
+			continue
+		}
+
+		for _, importInfo := range importInfoGroup.importInfos {
+			_, reason := classifyImportPathWithReason(importInfo.path, verifyOptions)
+			expectedImportPosition := expectedImportPositions[importInfo]
+
+			importExplanations = append(importExplanations, ImportExplanation{
+				LineNum:              importInfo.lineNum,
+				Path:                 importInfo.path,
+				Name:                 importInfo.name,
+				GroupIndex:           groupIndex,
+				Type:                 importTypeName[importInfo.classifiedType],
+				Reason:               reason,
+				ExpectedGroupIndex:   expectedImportPosition.groupIndex,
+				ExpectedIndexInGroup: expectedImportPosition.indexInGroup,
+			})
+		}
+
+		groupIndex++
+	}
+
+	return importExplanations, nil
+}
+
+// getExpectedImportPositions returns where the scheme expects each import - which is where the fixer
+// would move it to
+func getExpectedImportPositions(fixer *fixer,
+	importInfoGroups []importInfoGroup) (map[*importInfo]importPosition, error) {
+	var fixedImports []*fixedImport
+	importInfosByFixedImport := map[*fixedImport]*importInfo{}
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			fixedImport := &fixedImport{
+				name:           importInfo.name,
+				path:           importInfo.path,
+				classifiedType: importInfo.classifiedType,
+			}
+
+			fixedImports = append(fixedImports, fixedImport)
+			importInfosByFixedImport[fixedImport] = importInfo
+		}
+	}
+
+	fixedImportGroups, err := fixer.groupFixedImports(fixedImports)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedImportPositions := map[*importInfo]importPosition{}
+
+	for groupIndex, fixedImportGroup := range fixedImportGroups {
+		for indexInGroup, fixedImport := range fixedImportGroup {
+			expectedImportPositions[importInfosByFixedImport[fixedImport]] = importPosition{
+				groupIndex:   groupIndex,
+				indexInGroup: indexInGroup,
+			}
+		}
+	}
+
+	return expectedImportPositions, nil
+}
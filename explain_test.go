@@ -0,0 +1,101 @@
+package impi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExplainTestSuite struct {
+	suite.Suite
+	verifier *verifier
+}
+
+func (s *ExplainTestSuite) SetupTest() {
+	var err error
+
+	s.verifier, err = newVerifier()
+	s.Require().NoError(err)
+}
+
+func (s *ExplainTestSuite) TestExplain() {
+	contents := `package fixtures
+
+import (
+	"os"
+	"github.com/pavius/impi/foo"
+
+	// comment
+	bar "github.com/other/bar"
+	"fmt"
+)
+`
+
+	importExplanations, err := s.verifier.explain("fixtures.go", strings.NewReader(contents), &VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal([]ImportExplanation{
+		{
+			LineNum:              4,
+			Path:                 "os",
+			GroupIndex:           0,
+			Type:                 "Std",
+			Reason:               `the path has no "." so it's a standard library path`,
+			ExpectedGroupIndex:   0,
+			ExpectedIndexInGroup: 1,
+		},
+		{
+			LineNum:              5,
+			Path:                 "github.com/pavius/impi/foo",
+			GroupIndex:           0,
+			Type:                 "Local",
+			Reason:               "the path starts with the local prefix github.com/pavius/impi",
+			ExpectedGroupIndex:   1,
+			ExpectedIndexInGroup: 0,
+		},
+		{
+			LineNum:              8,
+			Path:                 "github.com/other/bar",
+			Name:                 "bar",
+			GroupIndex:           1,
+			Type:                 "Third party",
+			Reason:               `the path has a "." and doesn't start with the local prefix github.com/pavius/impi`,
+			ExpectedGroupIndex:   2,
+			ExpectedIndexInGroup: 0,
+		},
+		{
+			LineNum:              9,
+			Path:                 "fmt",
+			GroupIndex:           1,
+			Type:                 "Std",
+			Reason:               `the path has no "." so it's a standard library path`,
+			ExpectedGroupIndex:   0,
+			ExpectedIndexInGroup: 0,
+		},
+	}, importExplanations)
+}
+
+func (s *ExplainTestSuite) TestExplainWithoutLocalPrefix() {
+	contents := `package fixtures
+
+import (
+	"github.com/other/bar"
+)
+`
+
+	importExplanations, err := s.verifier.explain("fixtures.go", strings.NewReader(contents), &VerifyOptions{
+		Scheme: ImportGroupVerificationSchemeStdThirdPartyLocal,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(importExplanations, 1)
+	s.Require().Equal("Local or third party", importExplanations[0].Type)
+	s.Require().Equal(`the path has a "." and no local prefix is specified`, importExplanations[0].Reason)
+}
+
+func TestExplainTestSuite(t *testing.T) {
+	suite.Run(t, new(ExplainTestSuite))
+}
@@ -0,0 +1,434 @@
+package impi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type fixer struct {
+	verifyOptions        *VerifyOptions
+	filePath             string
+	packageUnderTestPath string
+}
+
+// fixedImport is an import as the fixer renders it, along with the comments attached to it. Header
+// comments head the group of the import rather than the import itself
+type fixedImport struct {
+	name           string
+	path           string
+	headerComments []string
+	docComments    []string
+	lineComments   []string
+	classifiedType importType
+}
+
+func newFixer() (*fixer, error) {
+	return &fixer{}, nil
+}
+
+// fix rewrites the import declarations of a source file into a single declaration. Banned imports are
+// replaced where a drop-in replacement exists and imports are regrouped and sorted according to the scheme.
+// Comments attached to imports are kept with them - if there are comments which aren't, fix fails rather
+// than lose them
+func (f *fixer) fix(filePath string, source []byte, verifyOptions *VerifyOptions) ([]byte, error) {
+	f.verifyOptions = verifyOptions
+	f.filePath = filePath
+
+	sourceFileSet := token.NewFileSet()
+
+	sourceNode, err := parser.ParseFile(sourceFileSet, "", source, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	f.packageUnderTestPath, err = getPackageUnderTestPath(filePath, sourceNode.Name.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// get the import declarations we're going to rewrite
+	importDecls, err := f.getImportDecls(sourceNode)
+	if err != nil {
+		return nil, err
+	}
+
+	// if there's nothing, do nothing
+	if len(importDecls) == 0 {
+		return source, nil
+	}
+
+	fixedImports, err := f.getFixedImports(sourceFileSet, sourceNode, importDecls)
+	if err != nil {
+		return nil, err
+	}
+
+	// imports which are redundant given another import of the same path are removed
+	fixedImports = collapseDuplicateImports(fixedImports)
+
+	fixedImportGroups, err := f.groupFixedImports(fixedImports)
+	if err != nil {
+		return nil, err
+	}
+
+	renderedImportDecl, err := f.renderImportDecl(fixedImportGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	// replace the first import declaration with the rendered one and remove the others
+	var fixedSource []byte
+	lastOffset := 0
+
+	for importDeclIndex, importDecl := range importDecls {
+		fixedSource = append(fixedSource, source[lastOffset:sourceFileSet.Position(getImportDeclPos(importDecl)).Offset]...)
+		lastOffset = sourceFileSet.Position(importDecl.End()).Offset
+
+		if importDeclIndex == 0 {
+			fixedSource = append(fixedSource, renderedImportDecl...)
+			continue
+		}
+
+		// don't leave the line of a removed declaration behind, nor two empty lines where it was
+		if lastOffset < len(source) && source[lastOffset] == '\n' {
+			lastOffset++
+		}
+
+		if lastOffset < len(source) && source[lastOffset] == '\n' && bytes.HasSuffix(fixedSource, []byte("\n\n")) {
+			lastOffset++
+		}
+	}
+
+	return append(fixedSource, source[lastOffset:]...), nil
+}
+
+// getImportDecls returns the import declarations of the file, except for `import "C"` which must
+// remain where it is
+func (f *fixer) getImportDecls(sourceNode *ast.File) ([]*ast.GenDecl, error) {
+	var importDecls []*ast.GenDecl
+
+	for _, decl := range sourceNode.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		importsC := false
+		for _, spec := range genDecl.Specs {
+			if spec.(*ast.ImportSpec).Path.Value == `"C"` {
+				importsC = true
+			}
+		}
+
+		if importsC {
+			if len(genDecl.Specs) != 1 {
+				return nil, errors.New(`import "C" must be declared on its own`)
+			}
+
+			continue
+		}
+
+		importDecls = append(importDecls, genDecl)
+	}
+
+	return importDecls, nil
+}
+
+// getImportDeclDoc returns the comment documenting a declaration of a single import, which is moved along
+// with the import
+func getImportDeclDoc(importDecl *ast.GenDecl) *ast.CommentGroup {
+	if len(importDecl.Specs) != 1 {
+		return nil
+	}
+
+	return importDecl.Doc
+}
+
+// getImportDeclPos returns where the declaration starts, including the comment moved along with its import
+func getImportDeclPos(importDecl *ast.GenDecl) token.Pos {
+	if importDeclDoc := getImportDeclDoc(importDecl); importDeclDoc != nil {
+		return importDeclDoc.Pos()
+	}
+
+	return importDecl.Pos()
+}
+
+// getFixedImports returns the imports of the declarations, with their comments and banned imports replaced
+func (f *fixer) getFixedImports(sourceFileSet *token.FileSet,
+	sourceNode *ast.File,
+	importDecls []*ast.GenDecl) ([]*fixedImport, error) {
+	var fixedImports []*fixedImport
+
+	attachedCommentGroups := map[*ast.CommentGroup]bool{}
+
+	// comments heading groups only matter if grouping rules take them into account
+	keepGroupHeaders := f.verifyOptions.CommentGroupBoundaries || len(f.verifyOptions.RequireGroupHeaders) != 0
+
+	for _, importDecl := range importDecls {
+		for specIndex, spec := range importDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+
+			importPath, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			// use drop-in replacements of banned imports
+			if bannedImport := findBannedImport(f.verifyOptions.BannedImports, importPath); bannedImport != nil {
+				if replacement := bannedImport.getReplacement(importPath); replacement != "" {
+					importPath = replacement
+				}
+			}
+
+			// the comment documenting a declaration of a single import documents that import
+			docComments := append(getCommentTexts(getImportDeclDoc(importDecl)), getCommentTexts(importSpec.Doc)...)
+
+			fixedImport := &fixedImport{
+				name:           getImportSpecName(importSpec),
+				path:           importPath,
+				docComments:    docComments,
+				lineComments:   getCommentTexts(importSpec.Comment),
+				classifiedType: classifyImportPath(importPath, f.verifyOptions),
+			}
+
+			if keepGroupHeaders && isGroupHeader(sourceFileSet, importDecl, specIndex) {
+				fixedImport.headerComments = fixedImport.docComments
+				fixedImport.docComments = nil
+			}
+
+			fixedImports = append(fixedImports, fixedImport)
+
+			attachedCommentGroups[importSpec.Doc] = true
+			attachedCommentGroups[importSpec.Comment] = true
+		}
+
+		attachedCommentGroups[getImportDeclDoc(importDecl)] = true
+	}
+
+	// comments between the imports which aren't attached to any import (e.g. separated by an empty line)
+	// have no place in the rewritten declaration
+	startPos := getImportDeclPos(importDecls[0])
+	endPos := importDecls[len(importDecls)-1].End()
+
+	for _, commentGroup := range sourceNode.Comments {
+		if commentGroup.Pos() > startPos && commentGroup.End() < endPos && !attachedCommentGroups[commentGroup] {
+			return nil, fmt.Errorf("Comment on line %d is not attached to an import",
+				sourceFileSet.Position(commentGroup.Pos()).Line)
+		}
+	}
+
+	return fixedImports, nil
+}
+
+// groupFixedImports groups the imports in the order the scheme dictates, splits the groups into their
+// sub-groups and sorts each group
+func (f *fixer) groupFixedImports(fixedImports []*fixedImport) ([][]*fixedImport, error) {
+	verificationScheme, err := getVerificationScheme(f.verifyOptions.getScheme(f.filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	importSubgrouper, err := newImportSubgrouper(f.filePath, f.verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	importOrder := getFullImportOrder(verificationScheme)
+
+	// imports of types the scheme doesn't order go after those it does, followed by the package under test
+	// and by blank imports if they reside in their own groups
+	unorderedGroupIndex := len(importOrder)
+	packageUnderTestGroupIndex := len(importOrder) + 1
+	blankGroupIndex := len(importOrder) + 2
+	fixedImportGroups := make([][]*fixedImport, len(importOrder)+3)
+
+	packageUnderTestPlacement := f.verifyOptions.PackageUnderTest
+	if f.packageUnderTestPath == "" {
+		packageUnderTestPlacement = PackageUnderTestPlacementLocal
+	}
+
+	for _, fixedImport := range fixedImports {
+		groupIndex := unorderedGroupIndex
+		isPackageUnderTest := packageUnderTestPlacement != PackageUnderTestPlacementLocal &&
+			fixedImport.path == f.packageUnderTestPath
+
+		if f.verifyOptions.BlankImportsLast && fixedImport.name == "_" {
+			groupIndex = blankGroupIndex
+		} else if isPackageUnderTest && packageUnderTestPlacement == PackageUnderTestPlacementLastGroup {
+			groupIndex = packageUnderTestGroupIndex
+		} else if importTypeIndex := findImportTypeInImportTypeSlice(importOrder, fixedImport.classifiedType); importTypeIndex != -1 {
+			groupIndex = importTypeIndex
+		}
+
+		fixedImportGroups[groupIndex] = append(fixedImportGroups[groupIndex], fixedImport)
+	}
+
+	var nonEmptyFixedImportGroups [][]*fixedImport
+
+	for fixedImportGroupIndex, fixedImportGroup := range fixedImportGroups {
+		if len(fixedImportGroup) == 0 {
+			continue
+		}
+
+		sort.SliceStable(fixedImportGroup, func(i, j int) bool {
+
+			// the package under test precedes the other imports of its group
+			if packageUnderTestPlacement == PackageUnderTestPlacementFirstLocal &&
+				fixedImportGroup[i].path != fixedImportGroup[j].path &&
+				(fixedImportGroup[i].path == f.packageUnderTestPath || fixedImportGroup[j].path == f.packageUnderTestPath) {
+				return fixedImportGroup[i].path == f.packageUnderTestPath
+			}
+
+			return f.verifyOptions.SortOrder.less(
+				importSortKey{name: fixedImportGroup[i].name, path: fixedImportGroup[i].path},
+				importSortKey{name: fixedImportGroup[j].name, path: fixedImportGroup[j].path})
+		})
+
+		// the groups of the package under test and of blank imports are not subject to the scheme
+		if fixedImportGroupIndex == packageUnderTestGroupIndex || fixedImportGroupIndex == blankGroupIndex {
+			nonEmptyFixedImportGroups = append(nonEmptyFixedImportGroups, fixedImportGroup)
+			continue
+		}
+
+		nonEmptyFixedImportGroups = append(nonEmptyFixedImportGroups,
+			importSubgrouper.splitFixedImportGroup(fixedImportGroup)...)
+	}
+
+	// header comments head the groups their imports end up in
+	for _, fixedImportGroup := range nonEmptyFixedImportGroups {
+		var headerComments []string
+
+		for _, groupFixedImport := range fixedImportGroup {
+			headerComments = append(headerComments, groupFixedImport.headerComments...)
+			groupFixedImport.headerComments = nil
+		}
+
+		fixedImportGroup[0].headerComments = headerComments
+	}
+
+	return nonEmptyFixedImportGroups, nil
+}
+
+// renderImportDecl renders the groups as a single, gofmt formatted import declaration
+func (f *fixer) renderImportDecl(fixedImportGroups [][]*fixedImport) ([]byte, error) {
+	const renderedFileHeader = "package p\n\n"
+
+	var buffer bytes.Buffer
+
+	// format a file holding only the declaration, so that comments are aligned the way gofmt aligns them
+	buffer.WriteString(renderedFileHeader + "import (\n")
+
+	for fixedImportGroupIndex, fixedImportGroup := range fixedImportGroups {
+		if fixedImportGroupIndex != 0 {
+			buffer.WriteString("\n")
+		}
+
+		for _, fixedImport := range fixedImportGroup {
+			for _, headerComment := range fixedImport.headerComments {
+				buffer.WriteString(headerComment + "\n")
+			}
+
+			for _, docComment := range fixedImport.docComments {
+				buffer.WriteString(docComment + "\n")
+			}
+
+			if fixedImport.name != "" {
+				buffer.WriteString(fixedImport.name + " ")
+			}
+
+			buffer.WriteString(strconv.Quote(fixedImport.path))
+
+			if len(fixedImport.lineComments) != 0 {
+				buffer.WriteString(" " + strings.Join(fixedImport.lineComments, " "))
+			}
+
+			buffer.WriteString("\n")
+		}
+	}
+
+	buffer.WriteString(")\n")
+
+	// print the way gofmt does, except that imports are not sorted - they're already sorted in the sort
+	// order, which may differ from that of gofmt
+	renderedFileSet := token.NewFileSet()
+
+	renderedFileNode, err := parser.ParseFile(renderedFileSet, "", buffer.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var formattedFile bytes.Buffer
+
+	printerConfig := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := printerConfig.Fprint(&formattedFile, renderedFileSet, renderedFileNode); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(bytes.TrimPrefix(formattedFile.Bytes(), []byte(renderedFileHeader)), []byte("\n")), nil
+}
+
+// getFullImportOrder returns the longest group order the scheme allows
+func getFullImportOrder(verificationScheme verificationScheme) []importType {
+	var fullImportOrder []importType
+
+	for _, allowedImportOrder := range verificationScheme.getAllowedImportOrders() {
+		if len(allowedImportOrder) > len(fullImportOrder) {
+			fullImportOrder = allowedImportOrder
+		}
+	}
+
+	return fullImportOrder
+}
+
+// isGroupHeader returns whether the doc comment of an import heads its group - that is, whether the import
+// is the first of its declaration or an empty line separates the comment from the previous import
+func isGroupHeader(sourceFileSet *token.FileSet, importDecl *ast.GenDecl, specIndex int) bool {
+	importSpec := importDecl.Specs[specIndex].(*ast.ImportSpec)
+	if importSpec.Doc == nil {
+		return false
+	}
+
+	if specIndex == 0 {
+		return true
+	}
+
+	previousImportSpec := importDecl.Specs[specIndex-1].(*ast.ImportSpec)
+
+	previousEnd := previousImportSpec.End()
+	if previousImportSpec.Comment != nil {
+		previousEnd = previousImportSpec.Comment.End()
+	}
+
+	return sourceFileSet.Position(importSpec.Doc.Pos()).Line > sourceFileSet.Position(previousEnd).Line+1
+}
+
+func getCommentTexts(commentGroup *ast.CommentGroup) []string {
+	if commentGroup == nil {
+		return nil
+	}
+
+	var commentTexts []string
+
+	for _, comment := range commentGroup.List {
+		commentTexts = append(commentTexts, comment.Text)
+	}
+
+	return commentTexts
+}
+
+func findImportTypeInImportTypeSlice(slice []importType, value importType) int {
+	for sliceValueIndex, sliceValue := range slice {
+		if sliceValue == value {
+			return sliceValueIndex
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,182 @@
+package impi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FixerTestSuite struct {
+	suite.Suite
+	fixer   *fixer
+	options VerifyOptions
+}
+
+type fixTestCase struct {
+	name                string
+	contents            string
+	expectedContents    string
+	expectedErrorString string
+}
+
+func (s *FixerTestSuite) SetupTest() {
+	var err error
+
+	s.fixer, err = newFixer()
+	s.Require().NoError(err)
+
+	s.options = VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}
+}
+
+func (s *FixerTestSuite) fixTestCases(fixTestCases []fixTestCase) {
+	for _, fixTestCase := range fixTestCases {
+		fixedContents, err := s.fixer.fix("fixtures.go", []byte(fixTestCase.contents), &s.options)
+
+		if fixTestCase.expectedErrorString != "" {
+			s.Require().Error(err, fixTestCase.name)
+			s.Require().Contains(err.Error(), fixTestCase.expectedErrorString, fixTestCase.name)
+			continue
+		}
+
+		s.Require().NoError(err, fixTestCase.name)
+		s.Require().Equal(fixTestCase.expectedContents, string(fixedContents), fixTestCase.name)
+	}
+}
+
+func (s *FixerTestSuite) TestRegroup() {
+	s.fixTestCases([]fixTestCase{
+		{
+			name: "Mixed groups",
+			contents: `package fixtures
+
+import (
+	"github.com/some/thirdparty"
+	"fmt"
+	// about a
+	"github.com/pavius/impi/a"
+
+	impi "github.com/pavius/impi"
+	"os" // about os
+)
+
+func main() {}
+`,
+			expectedContents: `package fixtures
+
+import (
+	"fmt"
+	"os" // about os
+
+	impi "github.com/pavius/impi"
+	// about a
+	"github.com/pavius/impi/a"
+
+	"github.com/some/thirdparty"
+)
+
+func main() {}
+`,
+		},
+		{
+			name: "Multiple declarations",
+			contents: `package fixtures
+
+import "github.com/some/thirdparty"
+import "fmt"
+
+func main() {}
+`,
+			expectedContents: `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/some/thirdparty"
+)
+
+func main() {}
+`,
+		},
+		{
+			name: "Documented declarations",
+			contents: `package fixtures
+
+// about thirdparty
+import "github.com/some/thirdparty"
+import "fmt"
+
+// justification
+import _ "embed"
+
+func main() {}
+`,
+			expectedContents: `package fixtures
+
+import (
+	// justification
+	_ "embed"
+	"fmt"
+
+	// about thirdparty
+	"github.com/some/thirdparty"
+)
+
+func main() {}
+`,
+		},
+		{
+			name: "Comment not attached to an import",
+			contents: `package fixtures
+
+import (
+	"github.com/some/thirdparty"
+
+	// a comment about nothing
+
+	"fmt"
+)
+`,
+			expectedErrorString: "Comment on line 6 is not attached to an import",
+		},
+	})
+}
+
+func (s *FixerTestSuite) TestReplaceBannedImports() {
+	s.options.BannedImports = []BannedImport{
+		{Path: "io/ioutil"},
+		{Path: "github.com/pkg/errors", Replacement: "errors"},
+		{Path: "golang.org/x/net/context", Replacement: "context"},
+	}
+
+	s.fixTestCases([]fixTestCase{
+		{
+			name: "Drop-in replacements",
+			contents: `package fixtures
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+`,
+			expectedContents: `package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+`,
+		},
+	})
+}
+
+func TestFixerTestSuite(t *testing.T) {
+	suite.Run(t, new(FixerTestSuite))
+}
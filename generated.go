@@ -0,0 +1,89 @@
+package impi
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"regexp"
+)
+
+// generatedRegex matches the comment marking a file as generated, as specified by https://golang.org/s/generatedcode
+var generatedRegex = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated returns whether the source file is generated: whether it has a line comment, before the
+// package clause, matching either the standard marker or any of the additional generated markers
+func (v *verifier) isGenerated(sourceFileReader io.ReadSeeker) (bool, error) {
+	sourceNode, err := parser.ParseFile(token.NewFileSet(), "", sourceFileReader, parser.PackageClauseOnly|parser.ParseComments)
+
+	// rewind for whoever reads the file next
+	if _, seekErr := sourceFileReader.Seek(0, io.SeekStart); seekErr != nil {
+		return false, seekErr
+	}
+
+	// files which fail to parse are reported as such once their imports are read
+	if err != nil {
+		return false, nil
+	}
+
+	generatedMarkerRegexes, err := v.getGeneratedMarkerRegexes()
+	if err != nil {
+		return false, err
+	}
+
+	for _, commentGroup := range sourceNode.Comments {
+		if commentGroup.Pos() > sourceNode.Package {
+			break
+		}
+
+		for _, comment := range commentGroup.List {
+			if generatedRegex.MatchString(comment.Text) {
+				return true, nil
+			}
+
+			for _, generatedMarkerRegex := range generatedMarkerRegexes {
+				if generatedMarkerRegex.MatchString(comment.Text) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// verifyGeneratedMarkers verifies that the additional generated markers compile, so that an invalid marker
+// fails the session up front rather than every file
+func verifyGeneratedMarkers(verifyOptions *VerifyOptions) error {
+	for _, generatedMarker := range verifyOptions.GeneratedMarkers {
+		if _, err := regexp.Compile(generatedMarker); err != nil {
+			return fmt.Errorf("Invalid generated marker %s: %s", generatedMarker, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// getGeneratedMarkerRegexes returns the compiled additional generated markers. Markers are compiled once per
+// verifier, having been verified along with the rest of the options
+func (v *verifier) getGeneratedMarkerRegexes() ([]*regexp.Regexp, error) {
+	var generatedMarkerRegexes []*regexp.Regexp
+
+	for _, generatedMarker := range v.verifyOptions.GeneratedMarkers {
+		generatedMarkerRegex, found := v.generatedMarkerRegexes[generatedMarker]
+		if !found {
+			var err error
+
+			generatedMarkerRegex, err = regexp.Compile(generatedMarker)
+			if err != nil {
+				return nil, err
+			}
+
+			v.generatedMarkerRegexes[generatedMarker] = generatedMarkerRegex
+		}
+
+		generatedMarkerRegexes = append(generatedMarkerRegexes, generatedMarkerRegex)
+	}
+
+	return generatedMarkerRegexes, nil
+}
@@ -0,0 +1,112 @@
+package impi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const invalidImports = `
+import (
+    "fmt"
+    "os"
+    "github.com/example/foo"
+    "path"
+)
+`
+
+type GeneratedFilesTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *GeneratedFilesTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.Generated = GeneratedFilesPolicySkip
+	s.options.GeneratedMarkers = []string{`^// Generated by legacygen`}
+}
+
+func (s *GeneratedFilesTestSuite) TestSkip() {
+	s.verifyTestCases([]verificationTestCase{
+		{
+			name:     "Standard marker before package clause (valid)",
+			contents: "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage fixtures\n" + invalidImports,
+		},
+		{
+			name:     "Additional marker before package clause (valid)",
+			contents: "/* license */\n\n// Generated by legacygen v1.2\npackage fixtures\n" + invalidImports,
+		},
+		{
+			name:     "Standard marker after package clause (invalid)",
+			contents: "package fixtures\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n" + invalidImports,
+			expectedErrorStrings: []string{
+				"Imports of different types are not allowed in the same group",
+			},
+		},
+		{
+			name: "Standard marker in a string literal (invalid)",
+			contents: "package fixtures\n" + invalidImports +
+				"\nconst header = `\n// Code generated by foo. DO NOT EDIT.\n`\n",
+			expectedErrorStrings: []string{
+				"Imports of different types are not allowed in the same group",
+			},
+		},
+		{
+			name:     "Marker in a block comment (invalid)",
+			contents: "/*\n// Code generated by foo. DO NOT EDIT.\n*/\n\npackage fixtures\n" + invalidImports,
+			expectedErrorStrings: []string{
+				"Imports of different types are not allowed in the same group",
+			},
+		},
+	})
+}
+
+func (s *GeneratedFilesTestSuite) TestReportOnly() {
+	s.options.Generated = GeneratedFilesPolicyReportOnly
+	defer func() { s.options.Generated = GeneratedFilesPolicySkip }()
+
+	err := s.verify("// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage fixtures\n" + invalidImports)
+	s.Require().Error(err)
+
+	violations, ok := err.(ruleViolations)
+	s.Require().True(ok)
+
+	for _, violation := range violations {
+		s.Require().True(violation.reportOnly)
+	}
+
+	// files which aren't generated are verified as usual
+	err = s.verify("package fixtures\n" + invalidImports)
+	s.Require().Error(err)
+	s.Require().False(err.(ruleViolations)[0].reportOnly)
+}
+
+func (s *GeneratedFilesTestSuite) TestGeneratedFilesPolicyNames() {
+	var generatedFilesPolicy GeneratedFilesPolicy
+
+	for _, name := range []string{"check", "skip", "report-only"} {
+		s.Require().NoError(generatedFilesPolicy.Set(name))
+		s.Require().Equal(name, generatedFilesPolicy.String())
+	}
+
+	s.Require().Error(generatedFilesPolicy.Set(strings.ToUpper("skip")))
+}
+
+func (s *GeneratedFilesTestSuite) TestInvalidGeneratedMarker() {
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	options := s.options
+	options.GeneratedMarkers = []string{`^// Generated by (legacygen`}
+
+	// invalid markers fail the session before any file is verified
+	err = impi.Verify(".", &options, &collectingErrorReporter{})
+	s.Require().Error(err)
+	s.Require().True(strings.HasPrefix(err.Error(), "Invalid generated marker"))
+	s.Require().Empty(impi.GetStats().NumFilesScanned)
+}
+
+func TestGeneratedFilesTestSuite(t *testing.T) {
+	suite.Run(t, new(GeneratedFilesTestSuite))
+}
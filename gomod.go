@@ -0,0 +1,112 @@
+package impi
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// goModule describes the module a directory belongs to, as declared in its go.mod
+type goModule struct {
+	rootDir  string
+	path     string
+	requires []string
+}
+
+// findGoModule returns the module the directory belongs to - the one declared in the nearest go.mod
+// at or above it. If there is no go.mod, nil is returned
+func findGoModule(dirPath string) (*goModule, error) {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		goModPath := filepath.Join(absDirPath, "go.mod")
+
+		if _, err := os.Stat(goModPath); err == nil {
+			return readGoModule(goModPath)
+		}
+
+		parentDirPath := filepath.Dir(absDirPath)
+		if parentDirPath == absDirPath {
+			return nil, nil
+		}
+
+		absDirPath = parentDirPath
+	}
+}
+
+func readGoModule(goModPath string) (*goModule, error) {
+	goModFile, err := os.Open(goModPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer goModFile.Close()
+
+	module := &goModule{
+		rootDir: filepath.Dir(goModPath),
+	}
+
+	s := bufio.NewScanner(goModFile)
+	inRequireBlock := false
+
+	for s.Scan() {
+		directive := strings.Fields(stripGoModComment(s.Text()))
+
+		switch {
+		case len(directive) == 0:
+			continue
+		case inRequireBlock && directive[0] == ")":
+			inRequireBlock = false
+		case inRequireBlock:
+			module.requires = append(module.requires, unquoteGoModString(directive[0]))
+		case len(directive) == 2 && directive[0] == "module":
+			module.path = unquoteGoModString(directive[1])
+		case len(directive) == 2 && directive[0] == "require" && directive[1] == "(":
+			inRequireBlock = true
+		case len(directive) >= 3 && directive[0] == "require":
+			module.requires = append(module.requires, unquoteGoModString(directive[1]))
+		}
+	}
+
+	return module, s.Err()
+}
+
+// getPackagePath returns the import path of the package in the directory, which must reside in the module
+func (gm *goModule) getPackagePath(dirPath string) (string, error) {
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	relDirPath, err := filepath.Rel(gm.rootDir, absDirPath)
+	if err != nil {
+		return "", err
+	}
+
+	if relDirPath == "." {
+		return gm.path, nil
+	}
+
+	return gm.path + "/" + filepath.ToSlash(relDirPath), nil
+}
+
+func stripGoModComment(line string) string {
+	if commentIndex := strings.Index(line, "//"); commentIndex != -1 {
+		return line[:commentIndex]
+	}
+
+	return line
+}
+
+func unquoteGoModString(value string) string {
+	if unquotedValue, err := strconv.Unquote(value); err == nil {
+		return unquotedValue
+	}
+
+	return value
+}
@@ -0,0 +1,263 @@
+package impi
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const impiIgnoreFileName = ".impiignore"
+
+// ignorePattern is a single gitignore-style pattern, declared in an ignore file
+type ignorePattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher tells whether paths are ignored by the ignore files in their directory and the directories
+// above it, up to the root of the git repository - the way git does
+type ignoreMatcher struct {
+	ignoreFileNames []string
+	patternsByDir   map[string][]*ignorePattern
+	ignoredDirs     map[string]bool
+}
+
+func newIgnoreMatcher(ignoreFileNames []string) *ignoreMatcher {
+	return &ignoreMatcher{
+		ignoreFileNames: ignoreFileNames,
+		patternsByDir:   map[string][]*ignorePattern{},
+		ignoredDirs:     map[string]bool{},
+	}
+}
+
+// isIgnored returns whether a path is ignored, either by itself or because a directory above it is
+func (im *ignoreMatcher) isIgnored(filePath string, isDir bool) (bool, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return false, err
+	}
+
+	rootDirPath := getIgnoreRootDir(absFilePath)
+
+	// a path can't be re-included if a directory above it is ignored
+	parentDirPath := filepath.Dir(absFilePath)
+	if parentDirPath != absFilePath && isPathUnderDir(parentDirPath, rootDirPath) && parentDirPath != rootDirPath {
+		ignored, err := im.isDirIgnored(parentDirPath, rootDirPath)
+		if err != nil || ignored {
+			return ignored, err
+		}
+	}
+
+	return im.matchPatterns(absFilePath, isDir, rootDirPath)
+}
+
+func (im *ignoreMatcher) isDirIgnored(absDirPath string, rootDirPath string) (bool, error) {
+	if ignored, found := im.ignoredDirs[absDirPath]; found {
+		return ignored, nil
+	}
+
+	ignored, err := im.isIgnored(absDirPath, true)
+	if err != nil {
+		return false, err
+	}
+
+	im.ignoredDirs[absDirPath] = ignored
+
+	return ignored, nil
+}
+
+// matchPatterns matches the path against the patterns of the ignore files above it. Patterns of deeper
+// ignore files, and later patterns within an ignore file, take precedence
+func (im *ignoreMatcher) matchPatterns(absFilePath string, isDir bool, rootDirPath string) (bool, error) {
+	ignored := false
+
+	for _, dirPath := range getDirsBetween(rootDirPath, filepath.Dir(absFilePath)) {
+		patterns, err := im.getDirPatterns(dirPath)
+		if err != nil {
+			return false, err
+		}
+
+		relFilePath, err := filepath.Rel(dirPath, absFilePath)
+		if err != nil {
+			return false, err
+		}
+
+		for _, pattern := range patterns {
+			if pattern.matches(filepath.ToSlash(relFilePath), isDir) {
+				ignored = !pattern.negate
+			}
+		}
+	}
+
+	return ignored, nil
+}
+
+// getDirPatterns returns the patterns declared in the ignore files of a directory
+func (im *ignoreMatcher) getDirPatterns(dirPath string) ([]*ignorePattern, error) {
+	if patterns, found := im.patternsByDir[dirPath]; found {
+		return patterns, nil
+	}
+
+	var patterns []*ignorePattern
+
+	for _, ignoreFileName := range im.ignoreFileNames {
+		ignoreFilePatterns, err := readIgnoreFile(filepath.Join(dirPath, ignoreFileName))
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, ignoreFilePatterns...)
+	}
+
+	im.patternsByDir[dirPath] = patterns
+
+	return patterns, nil
+}
+
+func (ip *ignorePattern) matches(relPath string, isDir bool) bool {
+	if ip.dirOnly && !isDir {
+		return false
+	}
+
+	pathSegments := strings.Split(relPath, "/")
+
+	// patterns without a slash match the name of the path at any depth
+	if !ip.anchored {
+		return matchIgnoreSegments(ip.segments, pathSegments[len(pathSegments)-1:])
+	}
+
+	return matchIgnoreSegments(ip.segments, pathSegments)
+}
+
+// matchIgnoreSegments matches path segments against pattern segments, where "**" matches any number of segments
+func matchIgnoreSegments(patternSegments []string, pathSegments []string) bool {
+	if len(patternSegments) == 0 {
+		return len(pathSegments) == 0
+	}
+
+	if patternSegments[0] == "**" {
+		for numSkippedSegments := 0; numSkippedSegments <= len(pathSegments); numSkippedSegments++ {
+			if matchIgnoreSegments(patternSegments[1:], pathSegments[numSkippedSegments:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(pathSegments) == 0 {
+		return false
+	}
+
+	if matched, err := path.Match(patternSegments[0], pathSegments[0]); err != nil || !matched {
+		return false
+	}
+
+	return matchIgnoreSegments(patternSegments[1:], pathSegments[1:])
+}
+
+// readIgnoreFile reads the patterns of an ignore file. A missing ignore file has no patterns
+func readIgnoreFile(ignoreFilePath string) ([]*ignorePattern, error) {
+	ignoreFile, err := os.Open(ignoreFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	defer ignoreFile.Close()
+
+	var patterns []*ignorePattern
+	s := bufio.NewScanner(ignoreFile)
+
+	for s.Scan() {
+		if pattern := parseIgnorePattern(s.Text()); pattern != nil {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns, s.Err()
+}
+
+// parseIgnorePattern parses a line of an ignore file, returning nil for empty and comment lines
+func parseIgnorePattern(line string) *ignorePattern {
+	line = strings.TrimRight(line, " \t\r")
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	pattern := &ignorePattern{}
+
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	}
+
+	// escaped leading characters are taken literally
+	line = strings.TrimPrefix(line, "\\")
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	// a slash at the beginning or in the middle anchors the pattern to the directory of the ignore file
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	pattern.segments = strings.Split(line, "/")
+
+	return pattern
+}
+
+// getIgnoreRootDir returns the root of the git repository holding the path. If the path isn't in a git
+// repository, the working directory is used if the path is under it, or the directory of the path otherwise
+func getIgnoreRootDir(absFilePath string) string {
+	if gitRootDirPath := findGitRootDir(filepath.Dir(absFilePath)); gitRootDirPath != "" {
+		return gitRootDirPath
+	}
+
+	if workingDirPath, err := os.Getwd(); err == nil && isPathUnderDir(absFilePath, workingDirPath) {
+		return workingDirPath
+	}
+
+	return filepath.Dir(absFilePath)
+}
+
+// findGitRootDir returns the root of the git repository holding the directory, or an empty string if it's
+// not in a git repository
+func findGitRootDir(absDirPath string) string {
+	for dirPath := absDirPath; ; dirPath = filepath.Dir(dirPath) {
+		if _, err := os.Stat(filepath.Join(dirPath, ".git")); err == nil {
+			return dirPath
+		}
+
+		if filepath.Dir(dirPath) == dirPath {
+			return ""
+		}
+	}
+}
+
+// getDirsBetween returns the directories from the root directory down to the directory, inclusive
+func getDirsBetween(rootDirPath string, dirPath string) []string {
+	var dirPaths []string
+
+	for ; isPathUnderDir(dirPath, rootDirPath); dirPath = filepath.Dir(dirPath) {
+		dirPaths = append([]string{dirPath}, dirPaths...)
+
+		if dirPath == rootDirPath || filepath.Dir(dirPath) == dirPath {
+			break
+		}
+	}
+
+	return dirPaths
+}
@@ -0,0 +1,79 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IgnoreMatcherTestSuite struct {
+	suite.Suite
+	tempDir       string
+	ignoreMatcher *ignoreMatcher
+}
+
+func (s *IgnoreMatcherTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-ignore")
+	s.Require().NoError(err)
+
+	s.Require().NoError(os.MkdirAll(path.Join(s.tempDir, ".git"), 0755))
+	s.Require().NoError(os.MkdirAll(path.Join(s.tempDir, "pkg", "api"), 0755))
+
+	s.writeFile(".gitignore", "# build outputs\n/build/\n*.pb.go\ngenerated/\n!keep.pb.go\n")
+	s.writeFile(".impiignore", "third_party/**/*.go\n")
+	s.writeFile("pkg/.gitignore", "api/legacy_*.go\n")
+	s.writeFile("pkg/api/.impiignore", "!legacy_keep.go\n")
+
+	s.ignoreMatcher = newIgnoreMatcher([]string{".gitignore", impiIgnoreFileName})
+}
+
+func (s *IgnoreMatcherTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *IgnoreMatcherTestSuite) writeFile(filePath string, contents string) {
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, filePath), []byte(contents), 0644))
+}
+
+func (s *IgnoreMatcherTestSuite) TestIsIgnored() {
+	for filePath, expectedIgnored := range map[string]bool{
+		"main.go":                      false,
+		"build/main.go":                true,
+		"cmd/build/main.go":            false,
+		"api.pb.go":                    true,
+		"pkg/api/api.pb.go":            true,
+		"pkg/api/keep.pb.go":           false,
+		"pkg/generated/types.go":       true,
+		"third_party/lib/lib.go":       true,
+		"third_party/lib/sub/lib.go":   true,
+		"pkg/third_party/lib/lib.go":   false,
+		"pkg/api/legacy_handler.go":    true,
+		"pkg/api/legacy_keep.go":       false,
+		"pkg/api/v1/legacy_handler.go": false,
+		"pkg/generated/keep.pb.go":     true,
+		"pkg/api/handler.go":           false,
+	} {
+		ignored, err := s.ignoreMatcher.isIgnored(path.Join(s.tempDir, filePath), false)
+		s.Require().NoError(err)
+		s.Require().Equal(expectedIgnored, ignored, filePath)
+	}
+}
+
+func (s *IgnoreMatcherTestSuite) TestIsDirIgnored() {
+	ignored, err := s.ignoreMatcher.isIgnored(path.Join(s.tempDir, "build"), true)
+	s.Require().NoError(err)
+	s.Require().True(ignored)
+
+	ignored, err = s.ignoreMatcher.isIgnored(path.Join(s.tempDir, "pkg"), true)
+	s.Require().NoError(err)
+	s.Require().False(ignored)
+}
+
+func TestIgnoreMatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(IgnoreMatcherTestSuite))
+}
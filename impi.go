@@ -1,14 +1,17 @@
 package impi
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"regexp"
 	"strings"
-
-	"github.com/kisielk/gotool"
+	"sync"
+	"time"
 )
 
 // Impi is a single instance that can perform verification on a path
@@ -19,6 +22,14 @@ type Impi struct {
 	stopChan        chan bool
 	verifyOptions   *VerifyOptions
 	SkipPathRegexes []*regexp.Regexp
+	ignoreMatcher   *ignoreMatcher
+	resultCache     *resultCache
+	optionsResolver *optionsResolver
+	statsLock       sync.Mutex
+	stats           Stats
+	packageFiles    map[string]fileStats
+	fileHandler     func(verifier *verifier, fixer *fixer, filePath string) error
+	readFile        func(filePath string) ([]byte, error)
 }
 
 // ImportGroupVerificationScheme specifies what to check when inspecting import groups
@@ -47,19 +58,557 @@ const (
 	ImportGroupVerificationSchemeStdThirdPartyLocal
 )
 
+var importGroupVerificationSchemeNames = map[ImportGroupVerificationScheme]string{
+	ImportGroupVerificationSchemeSingle:             "single",
+	ImportGroupVerificationSchemeStdNonStd:          "stdNonStd",
+	ImportGroupVerificationSchemeStdLocalThirdParty: "stdLocalThirdParty",
+	ImportGroupVerificationSchemeStdThirdPartyLocal: "stdThirdPartyLocal",
+}
+
+// String returns the name of the scheme
+func (igvs ImportGroupVerificationScheme) String() string {
+	return importGroupVerificationSchemeNames[igvs]
+}
+
+// Set sets the scheme from its name. Only supported schemes are accepted
+func (igvs *ImportGroupVerificationScheme) Set(name string) error {
+	switch name {
+	case "stdLocalThirdParty":
+		*igvs = ImportGroupVerificationSchemeStdLocalThirdParty
+	case "stdThirdPartyLocal":
+		*igvs = ImportGroupVerificationSchemeStdThirdPartyLocal
+	default:
+		return fmt.Errorf("Unsupported verification scheme: %s", name)
+	}
+
+	return nil
+}
+
+// MarshalText encodes the scheme as its name
+func (igvs ImportGroupVerificationScheme) MarshalText() ([]byte, error) {
+	return []byte(igvs.String()), nil
+}
+
+// UnmarshalText decodes the scheme from its name
+func (igvs *ImportGroupVerificationScheme) UnmarshalText(text []byte) error {
+	return igvs.Set(string(text))
+}
+
+// GeneratedFilesPolicy specifies how generated files are verified
+type GeneratedFilesPolicy int
+
+const (
+
+	// GeneratedFilesPolicyCheck verifies generated files like any other file
+	GeneratedFilesPolicyCheck = GeneratedFilesPolicy(iota)
+
+	// GeneratedFilesPolicySkip skips generated files
+	GeneratedFilesPolicySkip
+
+	// GeneratedFilesPolicyReportOnly reports violations in generated files, without failing verification
+	GeneratedFilesPolicyReportOnly
+)
+
+var generatedFilesPolicyNames = []string{
+	"check",
+	"skip",
+	"report-only",
+}
+
+// String returns the name of the policy
+func (gfp GeneratedFilesPolicy) String() string {
+	return generatedFilesPolicyNames[gfp]
+}
+
+// Set sets the policy from its name
+func (gfp *GeneratedFilesPolicy) Set(name string) error {
+	for generatedFilesPolicy, generatedFilesPolicyName := range generatedFilesPolicyNames {
+		if name == generatedFilesPolicyName {
+			*gfp = GeneratedFilesPolicy(generatedFilesPolicy)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unsupported generated files policy: %s", name)
+}
+
+// MarshalText encodes the policy as its name
+func (gfp GeneratedFilesPolicy) MarshalText() ([]byte, error) {
+	return []byte(gfp.String()), nil
+}
+
+// UnmarshalText decodes the policy from its name
+func (gfp *GeneratedFilesPolicy) UnmarshalText(text []byte) error {
+	return gfp.Set(string(text))
+}
+
+// TestFilesPolicy specifies how _test.go files are verified
+type TestFilesPolicy int
+
+const (
+
+	// TestFilesPolicyCheck verifies test files like any other file, against the test scheme if one is set
+	TestFilesPolicyCheck = TestFilesPolicy(iota)
+
+	// TestFilesPolicySkip skips test files
+	TestFilesPolicySkip
+
+	// TestFilesPolicyReportOnly reports violations in test files, without failing verification
+	TestFilesPolicyReportOnly
+)
+
+var testFilesPolicyNames = []string{
+	"check",
+	"skip",
+	"report-only",
+}
+
+// String returns the name of the policy
+func (tfp TestFilesPolicy) String() string {
+	return testFilesPolicyNames[tfp]
+}
+
+// Set sets the policy from its name
+func (tfp *TestFilesPolicy) Set(name string) error {
+	for testFilesPolicy, testFilesPolicyName := range testFilesPolicyNames {
+		if name == testFilesPolicyName {
+			*tfp = TestFilesPolicy(testFilesPolicy)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unsupported test files policy: %s", name)
+}
+
+// MarshalText encodes the policy as its name
+func (tfp TestFilesPolicy) MarshalText() ([]byte, error) {
+	return []byte(tfp.String()), nil
+}
+
+// UnmarshalText decodes the policy from its name
+func (tfp *TestFilesPolicy) UnmarshalText(text []byte) error {
+	return tfp.Set(string(text))
+}
+
+// PackageUnderTestPlacement specifies where external test packages (e.g. foo_test) import the package
+// they test
+type PackageUnderTestPlacement int
+
+const (
+
+	// PackageUnderTestPlacementLocal places the package under test like any other import of its class
+	PackageUnderTestPlacementLocal = PackageUnderTestPlacement(iota)
+
+	// PackageUnderTestPlacementFirstLocal places the package under test first in its group
+	PackageUnderTestPlacementFirstLocal
+
+	// PackageUnderTestPlacementLastGroup places the package under test in a trailing group of its own,
+	// which is not subject to the scheme. Only a trailing group of blank imports may follow it
+	PackageUnderTestPlacementLastGroup
+)
+
+var packageUnderTestPlacementNames = []string{
+	"local",
+	"first-local",
+	"last-group",
+}
+
+// String returns the name of the placement
+func (putp PackageUnderTestPlacement) String() string {
+	return packageUnderTestPlacementNames[putp]
+}
+
+// Set sets the placement from its name
+func (putp *PackageUnderTestPlacement) Set(name string) error {
+	for packageUnderTestPlacement, packageUnderTestPlacementName := range packageUnderTestPlacementNames {
+		if name == packageUnderTestPlacementName {
+			*putp = PackageUnderTestPlacement(packageUnderTestPlacement)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unsupported package under test placement: %s", name)
+}
+
+// MarshalText encodes the placement as its name
+func (putp PackageUnderTestPlacement) MarshalText() ([]byte, error) {
+	return []byte(putp.String()), nil
+}
+
+// UnmarshalText decodes the placement from its name
+func (putp *PackageUnderTestPlacement) UnmarshalText(text []byte) error {
+	return putp.Set(string(text))
+}
+
+// ImportSortOrder specifies how imports are sorted within a group
+type ImportSortOrder int
+
+const (
+
+	// ImportSortOrderLexical sorts imports by their path, byte-wise
+	ImportSortOrderLexical = ImportSortOrder(iota)
+
+	// ImportSortOrderGoimports sorts imports the way goimports does - by their path, then by their name
+	ImportSortOrderGoimports
+
+	// ImportSortOrderCaseInsensitive sorts imports by their path, ignoring case
+	ImportSortOrderCaseInsensitive
+
+	// ImportSortOrderParentFirst sorts imports by the elements of their path, so that a path precedes the
+	// paths under it and those follow it immediately (e.g. foo/bar, foo/bar/baz, foo/bar-baz)
+	ImportSortOrderParentFirst
+
+	// ImportSortOrderAlias sorts imports by the name they're referred by - their alias if they have one, or
+	// the last element of their path otherwise
+	ImportSortOrderAlias
+
+	// ImportSortOrderAliasFirst sorts aliased imports before imports which aren't, each by their path
+	ImportSortOrderAliasFirst
+)
+
+var importSortOrderNames = []string{
+	"lexical",
+	"goimports",
+	"case-insensitive",
+	"parent-first",
+	"alias",
+	"alias-first",
+}
+
+// String returns the name of the sort order
+func (iso ImportSortOrder) String() string {
+	return importSortOrderNames[iso]
+}
+
+// Set sets the sort order from its name
+func (iso *ImportSortOrder) Set(name string) error {
+	for importSortOrder, importSortOrderName := range importSortOrderNames {
+		if name == importSortOrderName {
+			*iso = ImportSortOrder(importSortOrder)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unsupported import sort order: %s", name)
+}
+
+// MarshalText encodes the sort order as its name
+func (iso ImportSortOrder) MarshalText() ([]byte, error) {
+	return []byte(iso.String()), nil
+}
+
+// UnmarshalText decodes the sort order from its name
+func (iso *ImportSortOrder) UnmarshalText(text []byte) error {
+	return iso.Set(string(text))
+}
+
+// Severity specifies how severe the findings of a rule are. Findings fail verification if they're at least
+// as severe as the fail-on threshold
+type Severity int
+
+const (
+
+	// SeverityError is the severity of the findings of all rules, unless specified otherwise
+	SeverityError = Severity(iota)
+
+	// SeverityWarning is for findings which should be addressed, e.g. of rules which aren't enforced yet
+	SeverityWarning
+
+	// SeverityInfo is for findings which are informational
+	SeverityInfo
+
+	// SeverityOff disables a rule - its findings aren't reported
+	SeverityOff
+)
+
+var severityNames = []string{
+	"error",
+	"warning",
+	"info",
+	"off",
+}
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// Set sets the severity from its name
+func (s *Severity) Set(name string) error {
+	for severity, severityName := range severityNames {
+		if name == severityName {
+			*s = Severity(severity)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unsupported severity: %s", name)
+}
+
+// MarshalText encodes the severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the severity from its name
+func (s *Severity) UnmarshalText(text []byte) error {
+	return s.Set(string(text))
+}
+
 // VerifyOptions specifies how to perform verification
 type VerifyOptions struct {
-	SkipTests       bool
-	Scheme          ImportGroupVerificationScheme
-	LocalPrefix     string
-	SkipPaths       []string
-	IgnoreGenerated bool
+	SkipTests       bool                          `json:"skip-tests,omitempty"`
+	Scheme          ImportGroupVerificationScheme `json:"scheme"`
+	LocalPrefix     string                        `json:"local,omitempty"`
+	SkipPaths       []string                      `json:"skip,omitempty"`
+	IgnoreGenerated bool                          `json:"ignore-generated,omitempty"`
+
+	// Tests specifies how _test.go files are verified. SkipTests is equivalent to TestFilesPolicySkip
+	Tests TestFilesPolicy `json:"tests,omitempty"`
+
+	// TestScheme is the scheme test files are verified against. If not set, they're verified against Scheme
+	TestScheme *ImportGroupVerificationScheme `json:"test-scheme,omitempty"`
+
+	// PackageUnderTest specifies where external test packages (e.g. foo_test) import the package they test
+	PackageUnderTest PackageUnderTestPlacement `json:"package-under-test,omitempty"`
+
+	// SkipUnparseable skips files which fail to parse, rather than reporting them
+	SkipUnparseable bool `json:"skip-unparseable,omitempty"`
+
+	// Generated specifies how generated files are verified. IgnoreGenerated is equivalent to
+	// GeneratedFilesPolicySkip
+	Generated GeneratedFilesPolicy `json:"generated,omitempty"`
+
+	// GeneratedMarkers are regular expressions matching comments which mark files as generated, in
+	// addition to the standard "// Code generated ... DO NOT EDIT." comment. Like the standard comment,
+	// they must appear before the package clause
+	GeneratedMarkers []string `json:"generated-markers,omitempty"`
+
+	// SortOrder specifies how imports are sorted within a group
+	SortOrder ImportSortOrder `json:"sort-order,omitempty"`
+
+	// Subgroups split the groups of classes of imports into ordered sub-groups, separated by empty lines.
+	// The scheme orders the classes as if each was a single group
+	Subgroups []ImportSubgroups `json:"subgroups,omitempty"`
+
+	// CommentGroupBoundaries treats comment lines following imports as the start of a new group, like
+	// empty lines
+	CommentGroupBoundaries bool `json:"comment-group-boundaries,omitempty"`
+
+	// RequireGroupHeaders lists classes of imports (std, local, third-party or local-or-third-party) whose
+	// groups must start with a header comment
+	RequireGroupHeaders []string `json:"require-group-headers,omitempty"`
+
+	// ForbidTrailingComments disallows comments following imports on the same line
+	ForbidTrailingComments bool `json:"forbid-trailing-comments,omitempty"`
+
+	// ForbidDotImports disallows dot imports in all files other than tests
+	ForbidDotImports bool `json:"forbid-dot-imports,omitempty"`
+
+	// RequireBlankImportComment requires blank imports to carry a comment justifying them
+	RequireBlankImportComment bool `json:"require-blank-import-comment,omitempty"`
+
+	// BlankImportsLast requires blank imports to reside in a dedicated, trailing group. This group
+	// is not subject to the scheme
+	BlankImportsLast bool `json:"blank-imports-last,omitempty"`
+
+	// ForbidRedundantAliases disallows aliases which are identical to the package name its import path
+	// implies, unless RequireAliases requires them
+	ForbidRedundantAliases bool `json:"forbid-redundant-aliases,omitempty"`
+
+	// ForbidDuplicateImports disallows importing the same path more than once in a file, whether under
+	// different names or once blank and once named
+	ForbidDuplicateImports bool `json:"forbid-duplicate-imports,omitempty"`
+
+	// RequireConsistentAliases requires the files of a package to import each path under the same name
+	RequireConsistentAliases bool `json:"require-consistent-aliases,omitempty"`
+
+	// RequireLowercaseAliases requires aliases to be lowercase, without underscores
+	RequireLowercaseAliases bool `json:"require-lowercase-aliases,omitempty"`
+
+	// RequireAliases requires an alias when the last element of the import path is not a valid
+	// identifier (e.g. gopkg.in/yaml.v3, github.com/foo/go-bar), is a major version or differs from the
+	// name the package declares. Package names are read for packages of the module of the file and
+	// packages under GOPATH
+	RequireAliases bool `json:"require-aliases,omitempty"`
+
+	// CanonicalAliases maps import paths to the alias they must be imported with. Canonical aliases
+	// may not be used for other import paths
+	CanonicalAliases map[string]string `json:"canonical-aliases,omitempty"`
+
+	// BannedImports lists import paths which may not be imported
+	BannedImports []BannedImport `json:"banned-imports,omitempty"`
+
+	// ImportBoundaries restrict which packages of the module may import which
+	ImportBoundaries []ImportBoundary `json:"import-boundaries,omitempty"`
+
+	// EnforceInternalImports applies Go's visibility rules to imports of internal packages
+	EnforceInternalImports bool `json:"enforce-internal-imports,omitempty"`
+
+	// ThirdPartyAllowlists restrict the third party imports of directory subtrees. A file is subject to
+	// the allowlist of the deepest directory containing it
+	ThirdPartyAllowlists []ThirdPartyAllowlist `json:"third-party-allowlists,omitempty"`
+
+	// BuildConstraints restricts verification to files which would be built under the constraints. If not
+	// set, all files are verified regardless of their constraints
+	BuildConstraints *BuildConstraints `json:"build-constraints,omitempty"`
+
+	// DisableGitignore verifies files even if .gitignore files ignore them. .impiignore files, which
+	// follow the same format, are always honored
+	DisableGitignore bool `json:"disable-gitignore,omitempty"`
+
+	// IncludeVendor verifies packages in vendor directories when walking packages (e.g. ./...)
+	IncludeVendor bool `json:"include-vendor,omitempty"`
+
+	// IncludeNestedModules verifies packages of modules nested in the walked directory (e.g. ./...)
+	IncludeNestedModules bool `json:"include-nested-modules,omitempty"`
+
+	// CacheDir is the directory in which verification results are cached, so that files which haven't
+	// changed since they were last verified with the same options aren't verified again. If not set,
+	// results aren't cached
+	CacheDir string `json:"cache-dir,omitempty"`
+
+	// Severities maps rules to the severity of their findings. Rules which aren't mapped are of SeverityError
+	Severities map[string]Severity `json:"severities,omitempty"`
+
+	// FailOn is the least severe severity of findings which fail verification. Less severe findings are
+	// only reported
+	FailOn Severity `json:"fail-on,omitempty"`
+
+	// Overrides override options for the files whose paths they match, in order. Nested configuration files
+	// (.impi.json) in directories under the working directory are applied after them, outermost first
+	Overrides []VerifyOptionsOverride `json:"overrides,omitempty"`
+
+	// Fix rewrites the import directives of files which fail verification, where possible
+	Fix bool `json:"fix,omitempty"`
+}
+
+// VerifyOptionsOverride overrides options for the files whose paths match it. Options which select the files
+// to verify (e.g. skip, tests: skip, build-constraints) and those of the session (e.g. cache-dir, fix) are
+// only honored in the root configuration
+type VerifyOptionsOverride struct {
+
+	// Paths are regular expressions matching the paths of the files the override applies to
+	Paths []string `json:"paths"`
+
+	// Options are the overridden options, in the format of the configuration file. Lists replace the
+	// overridden lists while maps are merged into the overridden maps
+	Options json.RawMessage `json:"options"`
+}
+
+// BannedImport specifies an import path, or a prefix of import paths, which may not be imported
+type BannedImport struct {
+
+	// Path is the banned import path. A path ending with "/..." bans the path and all paths under it
+	Path string `json:"path"`
+
+	// Message explains why the import is banned
+	Message string `json:"message,omitempty"`
+
+	// Replacement is a drop-in replacement of the banned path, used when fixing. If the banned path
+	// ends with "/...", the replacement must too
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// ImportBoundary specifies packages of the module that may not be imported by other packages of the module.
+// Packages are specified as patterns relative to the module root (e.g. pkg/api/...), where the module is
+// the one declared in the nearest go.mod or, if there's none, the local prefix
+type ImportBoundary struct {
+
+	// Name identifies the boundary in reports
+	Name string `json:"name"`
+
+	// Packages are the importing packages the boundary applies to
+	Packages []string `json:"packages"`
+
+	// Deny are the packages which may not be imported
+	Deny []string `json:"deny"`
+
+	// Allow are packages which may be imported even though they are denied
+	Allow []string `json:"allow,omitempty"`
+}
+
+// ThirdPartyAllowlist specifies the third party imports allowed in a directory subtree. An import is allowed
+// if it matches any of the prefixes, any of the prefixes listed in the file or any of the modules
+// required by the go.mod
+type ThirdPartyAllowlist struct {
+
+	// Dir is the root of the subtree
+	Dir string `json:"dir"`
+
+	// Prefixes are allowed import path prefixes (e.g. github.com/stretchr/testify)
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// File is the path of a file listing allowed import path prefixes, one per line. Lines starting
+	// with # are ignored
+	File string `json:"file,omitempty"`
+
+	// GoMod allows the modules required in the go.mod nearest to each file
+	GoMod bool `json:"go-mod,omitempty"`
 }
 
-// VerificationError holds an error and a file path on which the error occurred
+// ImportSubgroups specifies the sub-groups the group of a class of imports is split into. An import belongs
+// to the sub-group of the longest prefix it matches. Imports matching no prefix follow, either in a single
+// sub-group or in a sub-group per module, ordered by module path
+type ImportSubgroups struct {
+
+	// Class is the class of imports split into sub-groups - std, local, third-party or local-or-third-party
+	Class string `json:"class"`
+
+	// Prefixes are import path prefixes (e.g. golang.org/x), in the order of their sub-groups
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// ByModule places imports matching no prefix in a sub-group per module. The module of an import is
+	// the longest module required by the nearest go.mod that it matches or, if there is none, the
+	// repository root the import path implies (e.g. github.com/foo/bar)
+	ByModule bool `json:"by-module,omitempty"`
+}
+
+// BuildConstraints specifies the build configuration under which files are selected for verification
+type BuildConstraints struct {
+
+	// Tags are the build tags that are satisfied, in addition to the GOOS, GOARCH and compiler
+	Tags []string `json:"tags,omitempty"`
+
+	// GOOS is the target operating system. If not set, the current one is used
+	GOOS string `json:"goos,omitempty"`
+
+	// GOARCH is the target architecture. If not set, the current one is used
+	GOARCH string `json:"goarch,omitempty"`
+}
+
+// VerificationError holds an error and a file path on which the error occurred, along with the kind of
+// the finding. If the error was raised by a specific rule, the rule name and the line are set as well. Errors which are
+// only reported don't fail verification, nor do errors less severe than the fail-on threshold
 type VerificationError struct {
 	error
-	FilePath string
+	FilePath   string
+	Kind       string
+	Rule       string
+	LineNum    int
+	Column     int
+	ReportOnly bool
+	Severity   Severity
+}
+
+// ViolationsError is returned by Verify when findings fail verification. Stats tell whether any of them
+// are failures to verify files rather than rule violations
+type ViolationsError struct {
+	NumErrors int
+}
+
+func (ve *ViolationsError) Error() string {
+	return fmt.Sprintf("Found %d errors", ve.NumErrors)
+}
+
+// ConfigError is returned when the verification options or the packages to verify are invalid, as opposed to
+// failures to read or verify the files they select
+type ConfigError struct {
+	Err error
+}
+
+func (ce *ConfigError) Error() string {
+	return ce.Err.Error()
 }
 
 // ErrorReporter receives error reports as they are detected by the workers
@@ -82,33 +631,137 @@ func NewImpi(numWorkers int) (*Impi, error) {
 // Verify will iterate over the path and start verifying import correctness within
 // all .go files in the path. Path follows go tool semantics (e.g. ./...)
 func (i *Impi) Verify(rootPath string, verifyOptions *VerifyOptions, errorReporter ErrorReporter) error {
+	i.readFile = ioutil.ReadFile
+
+	return i.run(func() error {
+		return i.populatePathsChan(rootPath)
+	}, verifyOptions, errorReporter, i.verifyFile)
+}
+
+// VerifyFiles verifies the .go files among the file paths, reading their contents with readFile rather than
+// from disk if it's given (e.g. to verify the contents staged in git). Files are skipped like those of walked
+// packages, including those under directories which walking skips (e.g. vendor and testdata). Files can only
+// be fixed if they're read from disk
+func (i *Impi) VerifyFiles(filePaths []string,
+	readFile func(filePath string) ([]byte, error),
+	verifyOptions *VerifyOptions,
+	errorReporter ErrorReporter) error {
+	i.readFile = readFile
+
+	if readFile == nil {
+		i.readFile = ioutil.ReadFile
+	} else if verifyOptions.Fix {
+		return &ConfigError{Err: errors.New("Files which aren't read from disk can't be fixed")}
+	}
+
+	return i.run(func() error {
+		return i.populateFilePathsChan(filePaths)
+	}, verifyOptions, errorReporter, i.verifyFile)
+}
+
+// run handles all the .go files populated into the paths channel with the file handler, reporting the errors
+// it returns
+func (i *Impi) run(populatePathsChan func() error,
+	verifyOptions *VerifyOptions,
+	errorReporter ErrorReporter,
+	fileHandler func(verifier *verifier, fixer *fixer, filePath string) error) error {
+	i.stats = newStats()
+	i.packageFiles = map[string]fileStats{}
+	i.fileHandler = fileHandler
+
+	startTime := time.Now()
+	defer func() {
+		i.statsLock.Lock()
+		i.stats.Elapsed = time.Since(startTime)
+		i.statsLock.Unlock()
+	}()
+
+	if err := i.setVerifyOptions(verifyOptions); err != nil {
+		return err
+	}
+
+	// spin up the workers do handle all the data in the channel. workers will die
+	if err := i.createWorkers(i.numWorkers); err != nil {
+		return err
+	}
 
-	// save stuff for current session
+	// populate paths channel. paths channel will contain .go source file paths
+	if err := populatePathsChan(); err != nil {
+		return err
+	}
+
+	// wait for worker completion. if an error was reported, return error
+	if numErrors := i.waitWorkerCompletion(errorReporter); numErrors != 0 {
+		return &ViolationsError{NumErrors: numErrors}
+	}
+
+	return nil
+}
+
+// GetDirPaths returns the directories Verify would walk for the root path, including those which have no
+// go files (yet), or the directory of the file if the root path is a file
+func (i *Impi) GetDirPaths(rootPath string, verifyOptions *VerifyOptions) ([]string, error) {
+	if err := i.setVerifyOptions(verifyOptions); err != nil {
+		return nil, err
+	}
+
+	return i.getDirPaths(rootPath)
+}
+
+// setVerifyOptions saves the options of the current session, along with whatever is derived from them
+func (i *Impi) setVerifyOptions(verifyOptions *VerifyOptions) error {
 	i.verifyOptions = verifyOptions
+	i.SkipPathRegexes = nil
 
 	// compile skip regex
 	for _, skipPath := range verifyOptions.SkipPaths {
 		skipPathRegex, err := regexp.Compile(skipPath)
 		if err != nil {
-			return err
+			return &ConfigError{Err: err}
 		}
 
 		i.SkipPathRegexes = append(i.SkipPathRegexes, skipPathRegex)
 	}
 
-	// spin up the workers do handle all the data in the channel. workers will die
-	if err := i.createWorkers(i.numWorkers); err != nil {
-		return err
+	// .impiignore files come last so that they can re-include what .gitignore files ignore
+	ignoreFileNames := []string{impiIgnoreFileName}
+	if !verifyOptions.DisableGitignore {
+		ignoreFileNames = append([]string{".gitignore"}, ignoreFileNames...)
+	}
+
+	i.ignoreMatcher = newIgnoreMatcher(ignoreFileNames)
+
+	// invalid classes would otherwise fail every file
+	if _, err := getImportSubgroupsByType(verifyOptions); err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	if _, err := getRequiredGroupHeaderTypes(verifyOptions); err != nil {
+		return &ConfigError{Err: err}
 	}
 
-	// populate paths channel from path. paths channel will contain .go source file paths
-	if err := i.populatePathsChan(rootPath); err != nil {
+	if err := verifySeverities(verifyOptions); err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	if err := verifyGeneratedMarkers(verifyOptions); err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	optionsResolver, err := newOptionsResolver(verifyOptions)
+	if err != nil {
 		return err
 	}
 
-	// wait for worker completion. if an error was reported, return error
-	if numErrors := i.waitWorkerCompletion(errorReporter); numErrors != 0 {
-		return fmt.Errorf("Found %d errors", numErrors)
+	i.optionsResolver = optionsResolver
+
+	if verifyOptions.CacheDir != "" {
+		resultCache, err := newResultCache(verifyOptions.CacheDir, verifyOptions)
+		if err != nil {
+			return err
+		}
+
+		i.resultCache = resultCache
 	}
 
 	return nil
@@ -117,11 +770,17 @@ func (i *Impi) Verify(rootPath string, verifyOptions *VerifyOptions, errorReport
 func (i *Impi) populatePathsChan(rootPath string) error {
 	// TODO: this should be done in parallel
 
-	// get all the packages in the root path, following go 1.9 semantics
-	packagePaths := gotool.ImportPaths([]string{rootPath})
+	// close the channel to signify we won't add any more data
+	defer close(i.filePathsChan)
+
+	// get all the packages in the root path, following go tool semantics
+	packagePaths, err := i.getPackagePaths(rootPath)
+	if err != nil {
+		return err
+	}
 
 	if len(packagePaths) == 0 {
-		return fmt.Errorf("Could not find packages in %s", packagePaths)
+		return &ConfigError{Err: fmt.Errorf("Could not find packages in %s", rootPath)}
 	}
 
 	// iterate over these paths:
@@ -141,18 +800,44 @@ func (i *Impi) populatePathsChan(rootPath string) error {
 					continue
 				}
 
-				i.addFilePathToFilePathsChan(path.Join(packagePath, fileInfo.Name()))
+				if err := i.addFilePathToFilePathsChan(path.Join(packagePath, fileInfo.Name())); err != nil {
+					return err
+				}
 			}
 
 		} else {
 
 			// shove path to channel if passes filter
-			i.addFilePathToFilePathsChan(packagePath)
+			if err := i.addFilePathToFilePathsChan(packagePath); err != nil {
+				return err
+			}
 		}
 	}
 
+	return nil
+}
+
+// populateFilePathsChan populates the paths channel with the files, skipping those under directories which
+// walking packages skips
+func (i *Impi) populateFilePathsChan(filePaths []string) error {
+
 	// close the channel to signify we won't add any more data
-	close(i.filePathsChan)
+	defer close(i.filePathsChan)
+
+	for _, filePath := range filePaths {
+		skip, err := i.isUnderSkippedDir(filePath)
+		if err != nil {
+			return err
+		}
+
+		if skip {
+			continue
+		}
+
+		if err := i.addFilePathToFilePathsChan(filePath); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -160,12 +845,17 @@ func (i *Impi) populatePathsChan(rootPath string) error {
 func (i *Impi) waitWorkerCompletion(errorReporter ErrorReporter) int {
 	numWorkersComplete := 0
 	numErrorsReported := 0
+	filesWithViolations := map[string]bool{}
 
 	for result := range i.resultChan {
 		switch typedResult := result.(type) {
 		case VerificationError:
 			errorReporter.Report(typedResult)
-			numErrorsReported++
+			i.addVerificationErrorStats(&typedResult, filesWithViolations)
+
+			if i.isFailure(&typedResult) {
+				numErrorsReported++
+			}
 		case bool:
 			numWorkersComplete++
 		}
@@ -176,9 +866,24 @@ func (i *Impi) waitWorkerCompletion(errorReporter ErrorReporter) int {
 		}
 	}
 
+	// imports are verified across the files of each package once all the files are verified
+	for _, verificationError := range i.getInconsistentAliasErrors() {
+		errorReporter.Report(verificationError)
+		i.addVerificationErrorStats(&verificationError, filesWithViolations)
+
+		if i.isFailure(&verificationError) {
+			numErrorsReported++
+		}
+	}
+
 	return numErrorsReported
 }
 
+// isFailure returns whether a reported error fails verification
+func (i *Impi) isFailure(verificationError *VerificationError) bool {
+	return !verificationError.ReportOnly && verificationError.Severity <= i.verifyOptions.FailOn
+}
+
 func (i *Impi) createWorkers(numWorkers int) error {
 	for workerIndex := 0; workerIndex < numWorkers; workerIndex++ {
 		go i.verifyPathsFromChan()
@@ -195,24 +900,18 @@ func (i *Impi) verifyPathsFromChan() error {
 		return err
 	}
 
+	// create a fixer with which we'll fix modules that fail verification
+	fixer, err := newFixer()
+	if err != nil {
+		return err
+	}
+
 	// while we're not done
 	for filePath := range i.filePathsChan {
 
-		// open the file
-		file, err := os.Open(filePath)
-		if err != nil {
-			return err
-		}
-
 		// verify the path and report an error if one is found
-		if err = verifier.verify(file, i.verifyOptions); err != nil {
-			verificationError := VerificationError{
-				error:    err,
-				FilePath: filePath,
-			}
-
-			// write to results channel
-			i.resultChan <- verificationError
+		if err = i.fileHandler(verifier, fixer, filePath); err != nil {
+			i.reportVerificationError(filePath, err)
 		}
 	}
 
@@ -222,6 +921,159 @@ func (i *Impi) verifyPathsFromChan() error {
 	return nil
 }
 
+func (i *Impi) verifyFile(verifier *verifier, fixer *fixer, filePath string) error {
+	source, err := i.readFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	// skip files which wouldn't be built under the constraints
+	match, err := i.matchBuildConstraints(filePath, source)
+	if err != nil || !match {
+		return err
+	}
+
+	verifyOptions, err := i.optionsResolver.resolve(filePath)
+	if err != nil {
+		return err
+	}
+
+	// reuse the result of verifying the same contents, unless they need fixing
+	if i.resultCache != nil {
+		fileStats, violations, found := i.resultCache.get(filePath, source, verifyOptions)
+		if found && (violations == nil || !i.verifyOptions.Fix) {
+			i.addFileStats(filePath, fileStats)
+
+			if violations == nil {
+				return nil
+			}
+
+			return violations
+		}
+	}
+
+	// count the file by its last verification, which may follow a fix
+	defer func() {
+		i.addFileStats(filePath, &verifier.fileStats)
+	}()
+
+	err = i.verifyContents(verifier, filePath, source, verifyOptions)
+
+	// only files which violate rules are fixed, unless the violations are only reported or the file
+	// doesn't parse
+	violations, ok := err.(ruleViolations)
+	if !ok || !i.verifyOptions.Fix || violations[0].reportOnly || violations.hasParseViolation() {
+		return err
+	}
+
+	fixedSource, err := fixer.fix(filePath, source, verifyOptions)
+	if err != nil {
+		return append(violations, &ruleViolation{
+			message: fmt.Sprintf("Failed to fix imports: %s", err.Error()),
+		})
+	}
+
+	if err := writeFilePreservingMode(filePath, fixedSource); err != nil {
+		return err
+	}
+
+	i.addFixedFile()
+
+	// report whatever the fix did not take care of
+	return i.verifyContents(verifier, filePath, fixedSource, verifyOptions)
+}
+
+// matchBuildConstraints returns whether the file would be built under the build constraints, if there are any
+func (i *Impi) matchBuildConstraints(filePath string, source []byte) (bool, error) {
+	if i.verifyOptions.BuildConstraints == nil {
+		return true, nil
+	}
+
+	match, err := i.verifyOptions.BuildConstraints.matchFile(filePath, source)
+	if err != nil {
+		return false, err
+	}
+
+	if !match {
+		i.addFileStats(filePath, &fileStats{skipReason: SkipReasonBuildConstraints})
+	}
+
+	return match, nil
+}
+
+func (i *Impi) verifyContents(verifier *verifier, filePath string, source []byte, verifyOptions *VerifyOptions) error {
+	err := verifier.verify(filePath, bytes.NewReader(source), verifyOptions)
+
+	if i.resultCache != nil {
+		i.resultCache.put(filePath, source, verifyOptions, &verifier.fileStats, err)
+	}
+
+	return err
+}
+
+func (i *Impi) reportVerificationError(filePath string, err error) {
+
+	// errors not raised by rules are reported as is
+	violations, ok := err.(ruleViolations)
+	if !ok {
+		i.resultChan <- VerificationError{
+			error:    err,
+			FilePath: filePath,
+			Kind:     FindingKindFailure,
+		}
+
+		return
+	}
+
+	// report each violation separately
+	for _, violation := range violations {
+		i.resultChan <- VerificationError{
+			error:      violation,
+			FilePath:   filePath,
+			Kind:       violation.getFindingKind(),
+			Rule:       violation.rule,
+			LineNum:    violation.lineNum,
+			Column:     violation.column,
+			ReportOnly: violation.reportOnly,
+			Severity:   violation.severity,
+		}
+	}
+}
+
+func (vo *VerifyOptions) getTestFilesPolicy() TestFilesPolicy {
+	if vo.SkipTests {
+		return TestFilesPolicySkip
+	}
+
+	return vo.Tests
+}
+
+// getScheme returns the scheme the file is verified against
+func (vo *VerifyOptions) getScheme(filePath string) ImportGroupVerificationScheme {
+	if vo.TestScheme != nil && isTestFilePath(filePath) {
+		return *vo.TestScheme
+	}
+
+	return vo.Scheme
+}
+
+func (vo *VerifyOptions) getGeneratedFilesPolicy() GeneratedFilesPolicy {
+	if vo.IgnoreGenerated {
+		return GeneratedFilesPolicySkip
+	}
+
+	return vo.Generated
+}
+
+func writeFilePreservingMode(filePath string, contents []byte) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filePath, contents, fileInfo.Mode())
+}
+
 func isDir(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -231,25 +1083,40 @@ func isDir(path string) bool {
 	return info.IsDir()
 }
 
-func (i *Impi) addFilePathToFilePathsChan(filePath string) {
+func (i *Impi) addFilePathToFilePathsChan(filePath string) error {
 
 	// skip non-go files
 	if !strings.HasSuffix(filePath, ".go") {
-		return
+		return nil
 	}
 
 	// skip tests if not desired
-	if strings.HasSuffix(filePath, "_test.go") && i.verifyOptions.SkipTests {
-		return
+	if isTestFilePath(filePath) && i.verifyOptions.getTestFilesPolicy() == TestFilesPolicySkip {
+		i.addFileStats(filePath, &fileStats{skipReason: SkipReasonTest})
+		return nil
 	}
 
 	// cmd/impi/main.go should check the patters
 	for _, skipPathRegex := range i.SkipPathRegexes {
 		if skipPathRegex.Match([]byte(filePath)) {
-			return
+			i.addFileStats(filePath, &fileStats{skipReason: SkipReasonSkipPath})
+			return nil
 		}
 	}
 
+	// skip files ignored by .gitignore / .impiignore
+	ignored, err := i.ignoreMatcher.isIgnored(filePath, false)
+	if err != nil {
+		return err
+	}
+
+	if ignored {
+		i.addFileStats(filePath, &fileStats{skipReason: SkipReasonIgnored})
+		return nil
+	}
+
 	// write to paths chan
 	i.filePathsChan <- filePath
+
+	return nil
 }
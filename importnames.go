@@ -0,0 +1,219 @@
+package impi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// module paths of major versions 2 and above end with a version element (e.g. github.com/foo/bar/v2)
+var majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// verifyImportNames verifies dot, blank and aliased imports according to the verification options
+func (v *verifier) verifyImportNames(importInfoGroups []importInfoGroup) ruleViolations {
+	var violations ruleViolations
+
+	// canonical aliases are reserved for their import paths
+	canonicalAliasPaths := map[string]string{}
+	for importPath, alias := range v.verifyOptions.CanonicalAliases {
+		canonicalAliasPaths[alias] = importPath
+	}
+
+	for importInfoGroupIndex, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			violations = append(violations, v.verifyImportAlias(importInfo, canonicalAliasPaths)...)
+
+			switch importInfo.name {
+			case "":
+				continue
+
+			case ".":
+				if v.verifyOptions.ForbidDotImports && !isTestFilePath(v.filePath) {
+					violations = append(violations, &ruleViolation{
+						rule:    ruleDotImport,
+						lineNum: importInfo.lineNum,
+						message: fmt.Sprintf("Dot imports are only allowed in tests: %s", strings.TrimSpace(importInfo.lineValue)),
+					})
+				}
+
+			case "_":
+				if v.verifyOptions.RequireBlankImportComment && !importInfo.hasComment {
+					violations = append(violations, &ruleViolation{
+						rule:    ruleBlankImportComment,
+						lineNum: importInfo.lineNum,
+						message: fmt.Sprintf("Blank imports must be justified by a comment: %s", strings.TrimSpace(importInfo.lineValue)),
+					})
+				}
+
+				if v.verifyOptions.BlankImportsLast && importInfoGroupIndex != len(importInfoGroups)-1 {
+					violations = append(violations, &ruleViolation{
+						rule:    ruleBlankImportGroup,
+						lineNum: importInfo.lineNum,
+						message: fmt.Sprintf("Blank imports must reside in the last group: %s", strings.TrimSpace(importInfo.lineValue)),
+					})
+				}
+
+			default:
+				if v.verifyOptions.ForbidRedundantAliases &&
+					importInfo.name == getDefaultPackageName(importInfo.path) &&
+					!v.isAliasRequired(importInfo.path) {
+					violations = append(violations, &ruleViolation{
+						rule:    ruleRedundantAlias,
+						lineNum: importInfo.lineNum,
+						message: fmt.Sprintf("Alias is identical to the package name: %s", strings.TrimSpace(importInfo.lineValue)),
+					})
+				}
+			}
+		}
+	}
+
+	// if the last group holds blank imports, it must hold nothing else
+	if v.verifyOptions.BlankImportsLast && len(importInfoGroups) != 0 {
+		lastImportInfoGroup := importInfoGroups[len(importInfoGroups)-1]
+
+		if lastImportInfoGroup.hasBlankImports() && !lastImportInfoGroup.hasOnlyBlankImports() {
+			for _, importInfo := range lastImportInfoGroup.importInfos {
+				if importInfo.name != "_" {
+					violations = append(violations, &ruleViolation{
+						rule:    ruleBlankImportGroup,
+						lineNum: importInfo.lineNum,
+						message: fmt.Sprintf("Group of blank imports must not hold other imports: %s", strings.TrimSpace(importInfo.lineValue)),
+					})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// verifyImportAlias verifies the alias of an import (or lack thereof) against the alias conventions
+func (v *verifier) verifyImportAlias(importInfo *importInfo, canonicalAliasPaths map[string]string) ruleViolations {
+	var violations ruleViolations
+
+	// blank and dot imports aren't aliases
+	if importInfo.name == "_" || importInfo.name == "." {
+		return nil
+	}
+
+	// if the import path has a canonical alias, it must be used
+	if canonicalAlias, found := v.verifyOptions.CanonicalAliases[importInfo.path]; found {
+		if importInfo.name != canonicalAlias && (importInfo.name != "" || getDefaultPackageName(importInfo.path) != canonicalAlias) {
+			violations = append(violations, &ruleViolation{
+				rule:    ruleCanonicalAlias,
+				lineNum: importInfo.lineNum,
+				message: fmt.Sprintf("Expected %s to be imported as %s: %s",
+					importInfo.path,
+					canonicalAlias,
+					strings.TrimSpace(importInfo.lineValue)),
+			})
+		}
+
+		return violations
+	}
+
+	if importInfo.name == "" {
+		if v.isAliasRequired(importInfo.path) {
+			violations = append(violations, &ruleViolation{
+				rule:    ruleAliasRequired,
+				lineNum: importInfo.lineNum,
+				message: fmt.Sprintf("Import path does not imply the package name, an alias is required: %s",
+					strings.TrimSpace(importInfo.lineValue)),
+			})
+		}
+
+		return violations
+	}
+
+	// canonical aliases may not be used for other import paths
+	if canonicalAliasPath, found := canonicalAliasPaths[importInfo.name]; found {
+		violations = append(violations, &ruleViolation{
+			rule:    ruleCanonicalAlias,
+			lineNum: importInfo.lineNum,
+			message: fmt.Sprintf("Alias %s is reserved for %s: %s",
+				importInfo.name,
+				canonicalAliasPath,
+				strings.TrimSpace(importInfo.lineValue)),
+		})
+	}
+
+	if v.verifyOptions.RequireLowercaseAliases &&
+		(importInfo.name != strings.ToLower(importInfo.name) || strings.Contains(importInfo.name, "_")) {
+		violations = append(violations, &ruleViolation{
+			rule:    ruleAliasFormat,
+			lineNum: importInfo.lineNum,
+			message: fmt.Sprintf("Aliases must be lowercase and contain no underscores: %s",
+				strings.TrimSpace(importInfo.lineValue)),
+		})
+	}
+
+	return violations
+}
+
+// isAliasRequired returns whether aliases are required and the import path doesn't imply the package name - its
+// last element isn't a valid identifier, is a major version or differs from the name the package declares, if
+// the package can be resolved. An alias identical to the package name isn't redundant in that case
+func (v *verifier) isAliasRequired(importPath string) bool {
+	if !v.verifyOptions.RequireAliases {
+		return false
+	}
+
+	lastImportPathElement := getLastImportPathElement(importPath)
+
+	if !token.IsIdentifier(lastImportPathElement) || majorVersionRegex.MatchString(lastImportPathElement) {
+		return true
+	}
+
+	packageName := v.getPackageName(importPath)
+
+	return packageName != "" && packageName != lastImportPathElement
+}
+
+// filterBlankImportGroup removes the trailing group if it only holds blank imports
+func (v *verifier) filterBlankImportGroup(importInfoGroups []importInfoGroup) []importInfoGroup {
+	if len(importInfoGroups) == 0 || !importInfoGroups[len(importInfoGroups)-1].hasOnlyBlankImports() {
+		return importInfoGroups
+	}
+
+	return importInfoGroups[:len(importInfoGroups)-1]
+}
+
+func (iig *importInfoGroup) hasBlankImports() bool {
+	for _, importInfo := range iig.importInfos {
+		if importInfo.name == "_" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (iig *importInfoGroup) hasOnlyBlankImports() bool {
+	for _, importInfo := range iig.importInfos {
+		if importInfo.name != "_" {
+			return false
+		}
+	}
+
+	return len(iig.importInfos) != 0
+}
+
+// getImportSpecName returns the name an import spec was given, if any
+func getImportSpecName(importSpec *ast.ImportSpec) string {
+	if importSpec == nil || importSpec.Name == nil {
+		return ""
+	}
+
+	return importSpec.Name.Name
+}
+
+// getLastImportPathElement returns the last element of an import path, which is the package name by convention
+func getLastImportPathElement(importPath string) string {
+	return importPath[strings.LastIndex(importPath, "/")+1:]
+}
+
+func isTestFilePath(filePath string) bool {
+	return strings.HasSuffix(filePath, "_test.go")
+}
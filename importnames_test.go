@@ -0,0 +1,306 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ImportNamesTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *ImportNamesTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.ForbidDotImports = true
+	s.options.RequireBlankImportComment = true
+	s.options.BlankImportsLast = true
+	s.options.ForbidRedundantAliases = true
+}
+
+func (s *ImportNamesTestSuite) TestImportNames() {
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Named and justified blank imports (valid)",
+			contents: `package fixtures
+import (
+    "fmt"
+    strs "strings"
+
+    "github.com/pavius/impi/a"
+
+    goyaml "gopkg.in/yaml.v2"
+
+    // registers the driver
+    _ "github.com/lib/pq"
+    _ "image/png" // registers the decoder
+)
+`,
+		},
+		{
+			name: "Blank import declared on its own, justified by the comment of the declaration (valid)",
+			contents: `package fixtures
+
+// registers the driver
+import _ "github.com/lib/pq"
+`,
+		},
+		{
+			name: "Blank import declared on its own without comment (invalid)",
+			contents: `package fixtures
+
+import _ "github.com/lib/pq"
+`,
+			expectedErrorStrings: []string{
+				`Blank imports must be justified by a comment: import _ "github.com/lib/pq"`,
+			},
+		},
+		{
+			name: "Dot import (invalid)",
+			contents: `package fixtures
+import (
+    "fmt"
+    . "strings"
+)
+`,
+			expectedErrorStrings: []string{
+				`Dot imports are only allowed in tests: . "strings"`,
+			},
+		},
+		{
+			name: "Blank import without comment (invalid)",
+			contents: `package fixtures
+import (
+    "fmt"
+
+    _ "github.com/lib/pq"
+)
+`,
+			expectedErrorStrings: []string{
+				`Blank imports must be justified by a comment: _ "github.com/lib/pq"`,
+			},
+		},
+		{
+			name: "Blank import not in last group (invalid)",
+			contents: `package fixtures
+import (
+    "fmt"
+    _ "image/png" // registers the decoder
+
+    "github.com/pavius/impi/a"
+)
+`,
+			expectedErrorStrings: []string{
+				`Blank imports must reside in the last group: _ "image/png" // registers the decoder`,
+			},
+		},
+		{
+			name: "Blank import group holds other imports (invalid)",
+			contents: `package fixtures
+import (
+    "fmt"
+
+    "github.com/pavius/impi/a"
+    _ "image/png" // registers the decoder
+)
+`,
+			expectedErrorStrings: []string{
+				`Group of blank imports must not hold other imports: "github.com/pavius/impi/a"`,
+			},
+		},
+		{
+			name: "Redundant alias (invalid)",
+			contents: `package fixtures
+import (
+    "fmt"
+
+    impi "github.com/pavius/impi"
+
+    bar "github.com/foo/go-bar"
+    goyaml "gopkg.in/yaml.v2"
+    yaml "gopkg.in/yaml.v3"
+)
+`,
+			expectedErrorStrings: []string{
+				`Alias is identical to the package name: impi "github.com/pavius/impi"`,
+				`Alias is identical to the package name: bar "github.com/foo/go-bar"`,
+				`Alias is identical to the package name: yaml "gopkg.in/yaml.v3"`,
+			},
+			nonExpectedErrorStrings: []string{
+				"goyaml",
+			},
+		},
+	}
+	s.verifyTestCases(verificationTestCases)
+}
+
+func TestImportNamesTestSuite(t *testing.T) {
+	suite.Run(t, new(ImportNamesTestSuite))
+}
+
+type ImportNamesInTestsTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *ImportNamesInTestsTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.ForbidDotImports = true
+	s.filePath = "fixtures_test.go"
+}
+
+func (s *ImportNamesInTestsTestSuite) TestImportNames() {
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Dot import in test (valid)",
+			contents: `package fixtures
+import (
+    "fmt"
+    . "strings"
+)
+`,
+		},
+	}
+	s.verifyTestCases(verificationTestCases)
+}
+
+func TestImportNamesInTestsTestSuite(t *testing.T) {
+	suite.Run(t, new(ImportNamesInTestsTestSuite))
+}
+
+type AliasConventionsTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *AliasConventionsTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.RequireLowercaseAliases = true
+	s.options.RequireAliases = true
+
+	// aliases which are required are never redundant
+	s.options.ForbidRedundantAliases = true
+	s.options.CanonicalAliases = map[string]string{
+		"k8s.io/api/core/v1":    "corev1",
+		"github.com/pkg/errors": "errors",
+	}
+}
+
+func (s *AliasConventionsTestSuite) TestAliasConventions() {
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Conventional aliases (valid)",
+			contents: `package fixtures
+import (
+    "fmt"
+
+    bar "github.com/foo/go-bar"
+    "github.com/pkg/errors"
+    yaml "gopkg.in/yaml.v3"
+    corev1 "k8s.io/api/core/v1"
+)
+`,
+		},
+		{
+			name: "Alias format (invalid)",
+			contents: `package fixtures
+import (
+    "fmt"
+    myFmt "fmt"
+    my_fmt "fmt"
+)
+`,
+			expectedErrorStrings: []string{
+				`Aliases must be lowercase and contain no underscores: myFmt "fmt"`,
+				`Aliases must be lowercase and contain no underscores: my_fmt "fmt"`,
+			},
+		},
+		{
+			name: "Missing alias (invalid)",
+			contents: `package fixtures
+import (
+    "github.com/foo/bar/v2"
+    "github.com/foo/go-bar"
+    "gopkg.in/yaml.v3"
+)
+`,
+			expectedErrorStrings: []string{
+				`an alias is required: "github.com/foo/bar/v2"`,
+				`an alias is required: "github.com/foo/go-bar"`,
+				`an alias is required: "gopkg.in/yaml.v3"`,
+			},
+		},
+		{
+			name: "Canonical aliases (invalid)",
+			contents: `package fixtures
+import (
+    "k8s.io/api/core/v1"
+    pkgerrors "github.com/pkg/errors"
+    corev1 "k8s.io/api/core/v2"
+)
+`,
+			expectedErrorStrings: []string{
+				`Expected k8s.io/api/core/v1 to be imported as corev1: "k8s.io/api/core/v1"`,
+				`Expected github.com/pkg/errors to be imported as errors: pkgerrors "github.com/pkg/errors"`,
+				`Alias corev1 is reserved for k8s.io/api/core/v1: corev1 "k8s.io/api/core/v2"`,
+			},
+		},
+	}
+	s.verifyTestCases(verificationTestCases)
+}
+
+func (s *AliasConventionsTestSuite) TestPackageNameMismatch() {
+	tempDir, err := ioutil.TempDir("", "impi-aliases")
+	s.Require().NoError(err)
+
+	defer os.RemoveAll(tempDir)
+
+	for filePath, contents := range map[string]string{
+		"go.mod":     "module example.com/project\n",
+		"bar/bar.go": "package baz\n",
+		"foo/foo.go": "package foo\n",
+		"a.go": `package fixtures
+
+import (
+	"example.com/project/bar"
+	"example.com/project/foo"
+)
+`,
+		"b.go": `package fixtures
+
+import (
+	baz "example.com/project/bar"
+)
+`,
+	} {
+		s.Require().NoError(os.MkdirAll(path.Dir(path.Join(tempDir, filePath)), 0755))
+		s.Require().NoError(ioutil.WriteFile(path.Join(tempDir, filePath), []byte(contents), 0644))
+	}
+
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+	options := s.options
+	options.LocalPrefix = "example.com/project"
+	options.ForbidRedundantAliases = true
+
+	s.Require().Error(impi.Verify(tempDir, &options, errorReporter))
+
+	// packages whose name differs from the last element of their path require an alias, which isn't redundant
+	s.Require().Len(errorReporter.verificationErrors, 1)
+	s.Require().Equal(path.Join(tempDir, "a.go"), errorReporter.verificationErrors[0].FilePath)
+	s.Require().Equal(ruleAliasRequired, errorReporter.verificationErrors[0].Rule)
+	s.Require().Contains(errorReporter.verificationErrors[0].Error(), `"example.com/project/bar"`)
+}
+
+func TestAliasConventionsTestSuite(t *testing.T) {
+	suite.Run(t, new(AliasConventionsTestSuite))
+}
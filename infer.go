@@ -0,0 +1,237 @@
+package impi
+
+import (
+	"go/build"
+	"path/filepath"
+	"sort"
+)
+
+// SchemeConformance tells how many of the files verified follow a scheme with a local prefix and, for the
+// candidate of the loaded configuration, its sub-groups and sort order
+type SchemeConformance struct {
+	Scheme             ImportGroupVerificationScheme `json:"scheme"`
+	LocalPrefix        string                        `json:"local"`
+	Subgroups          []ImportSubgroups             `json:"subgroups,omitempty"`
+	SortOrder          ImportSortOrder               `json:"sort-order,omitempty"`
+	NumFiles           int                           `json:"files"`
+	NumConformingFiles int                           `json:"conforming-files"`
+}
+
+// IsConfigured returns whether the scheme is verified with the sub-groups or sort order of the loaded
+// configuration rather than on its own
+func (sc *SchemeConformance) IsConfigured() bool {
+	return len(sc.Subgroups) != 0 || sc.SortOrder != ImportSortOrderLexical
+}
+
+// GetPercentage returns the percentage of files which conform to the scheme
+func (sc *SchemeConformance) GetPercentage() float64 {
+	if sc.NumFiles == 0 {
+		return 100
+	}
+
+	return float64(sc.NumConformingFiles) * 100 / float64(sc.NumFiles)
+}
+
+type discardingErrorReporter struct{}
+
+func (der *discardingErrorReporter) Report(VerificationError) {}
+
+// InferSchemes verifies the files of the root paths against every supported scheme, with every candidate
+// local prefix, and returns how well the files conform to each - most conforming first. If the verification
+// options have sub-groups or a sort order, their scheme is a candidate with them as well, and is preferred
+// over schemes which conform as well. The local prefix of the verification options is the only candidate
+// if set. Otherwise, the candidates are the paths of the modules holding the root paths (or of their git
+// repositories under GOPATH). Only the options that select files are used - the options of other rules are
+// ignored, and files are never fixed
+func InferSchemes(rootPaths []string, verifyOptions *VerifyOptions, numWorkers int) ([]SchemeConformance, error) {
+	localPrefixes, err := getLocalPrefixCandidates(rootPaths, verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemeConformances []SchemeConformance
+
+	for _, schemeCandidate := range getSchemeCandidates(verifyOptions) {
+		for _, localPrefix := range localPrefixes {
+			schemeConformance := schemeCandidate
+			schemeConformance.LocalPrefix = localPrefix
+
+			inferenceOptions := getInferenceOptions(verifyOptions, &schemeConformance)
+
+			for _, rootPath := range rootPaths {
+				impiInstance, err := NewImpi(numWorkers)
+				if err != nil {
+					return nil, err
+				}
+
+				// verification fails whenever a file doesn't conform, which isn't a failure to infer
+				err = impiInstance.Verify(rootPath, inferenceOptions, &discardingErrorReporter{})
+				stats := impiInstance.GetStats()
+
+				if err != nil && stats.NumFilesWithViolations == 0 {
+					return nil, err
+				}
+
+				schemeConformance.NumFiles += stats.NumFilesScanned
+				schemeConformance.NumConformingFiles += stats.NumFilesScanned - stats.NumFilesWithViolations
+			}
+
+			schemeConformances = append(schemeConformances, schemeConformance)
+		}
+	}
+
+	sort.SliceStable(schemeConformances, func(first, second int) bool {
+		return schemeConformances[first].NumConformingFiles > schemeConformances[second].NumConformingFiles
+	})
+
+	return schemeConformances, nil
+}
+
+// getSchemeCandidates returns the schemes to infer, without their local prefixes - the scheme of the
+// verification options with their sub-groups and sort order if they have any, followed by every supported
+// scheme on its own. If the verification options have no supported scheme, their sub-groups and sort order
+// are tried with every supported scheme
+func getSchemeCandidates(verifyOptions *VerifyOptions) []SchemeConformance {
+	var configuredSchemeCandidates, schemeCandidates []SchemeConformance
+
+	for _, scheme := range getSupportedSchemes() {
+		schemeCandidates = append(schemeCandidates, SchemeConformance{Scheme: scheme})
+
+		configuredSchemeCandidate := SchemeConformance{
+			Scheme:    scheme,
+			Subgroups: verifyOptions.Subgroups,
+			SortOrder: verifyOptions.SortOrder,
+		}
+
+		if !configuredSchemeCandidate.IsConfigured() {
+			continue
+		}
+
+		if _, err := getVerificationScheme(verifyOptions.Scheme); err != nil || scheme == verifyOptions.Scheme {
+			configuredSchemeCandidates = append(configuredSchemeCandidates, configuredSchemeCandidate)
+		}
+	}
+
+	return append(configuredSchemeCandidates, schemeCandidates...)
+}
+
+// getSupportedSchemes returns the schemes files can be verified against
+func getSupportedSchemes() []ImportGroupVerificationScheme {
+	var schemes []ImportGroupVerificationScheme
+
+	for scheme := range importGroupVerificationSchemeNames {
+		if _, err := getVerificationScheme(scheme); err == nil {
+			schemes = append(schemes, scheme)
+		}
+	}
+
+	sort.Slice(schemes, func(first, second int) bool {
+		return schemes[first] < schemes[second]
+	})
+
+	return schemes
+}
+
+// getLocalPrefixCandidates returns the local prefixes to infer schemes with
+func getLocalPrefixCandidates(rootPaths []string, verifyOptions *VerifyOptions) ([]string, error) {
+	if verifyOptions.LocalPrefix != "" {
+		return []string{verifyOptions.LocalPrefix}, nil
+	}
+
+	var localPrefixes []string
+	foundLocalPrefixes := map[string]bool{}
+
+	for _, rootPath := range rootPaths {
+		dirPath, _, err := resolveRootPath(rootPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isDir(dirPath) {
+			dirPath = filepath.Dir(dirPath)
+		}
+
+		localPrefix, err := getDirImportPath(dirPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if localPrefix != "" && !foundLocalPrefixes[localPrefix] {
+			localPrefixes = append(localPrefixes, localPrefix)
+			foundLocalPrefixes[localPrefix] = true
+		}
+	}
+
+	// without a local prefix, local and third party imports can't be told apart
+	if len(localPrefixes) == 0 {
+		localPrefixes = []string{""}
+	}
+
+	return localPrefixes, nil
+}
+
+// getDirImportPath returns the path of the module the directory belongs to or, if it's not in a module,
+// the import path of its git repository (or of the directory itself, if it's not in one) under GOPATH.
+// An empty string is returned if neither is known
+func getDirImportPath(dirPath string) (string, error) {
+	module, err := findGoModule(dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	if module != nil {
+		return module.path, nil
+	}
+
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	if gitRootDirPath := findGitRootDir(absDirPath); gitRootDirPath != "" {
+		absDirPath = gitRootDirPath
+	}
+
+	return getGoPathImportPath(absDirPath)
+}
+
+// getGoPathImportPath returns the import path of a directory under GOPATH, or an empty string if it's
+// not under GOPATH
+func getGoPathImportPath(absDirPath string) (string, error) {
+	for _, goPath := range filepath.SplitList(build.Default.GOPATH) {
+		goPathSrcDirPath := filepath.Join(goPath, "src")
+
+		if absDirPath != goPathSrcDirPath && isPathUnderDir(absDirPath, goPathSrcDirPath) {
+			relDirPath, err := filepath.Rel(goPathSrcDirPath, absDirPath)
+			if err != nil {
+				return "", err
+			}
+
+			return filepath.ToSlash(relDirPath), nil
+		}
+	}
+
+	return "", nil
+}
+
+// getInferenceOptions returns the options selecting the same files as the verification options, verifying
+// them against the candidate scheme alone
+func getInferenceOptions(verifyOptions *VerifyOptions, schemeCandidate *SchemeConformance) *VerifyOptions {
+	return &VerifyOptions{
+		Scheme:               schemeCandidate.Scheme,
+		LocalPrefix:          schemeCandidate.LocalPrefix,
+		Subgroups:            schemeCandidate.Subgroups,
+		SortOrder:            schemeCandidate.SortOrder,
+		SkipTests:            verifyOptions.SkipTests,
+		Tests:                verifyOptions.Tests,
+		SkipPaths:            verifyOptions.SkipPaths,
+		IgnoreGenerated:      verifyOptions.IgnoreGenerated,
+		Generated:            verifyOptions.Generated,
+		GeneratedMarkers:     verifyOptions.GeneratedMarkers,
+		BuildConstraints:     verifyOptions.BuildConstraints,
+		DisableGitignore:     verifyOptions.DisableGitignore,
+		IncludeVendor:        verifyOptions.IncludeVendor,
+		IncludeNestedModules: verifyOptions.IncludeNestedModules,
+		CacheDir:             verifyOptions.CacheDir,
+	}
+}
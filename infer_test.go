@@ -0,0 +1,120 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InferTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (s *InferTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-infer")
+	s.Require().NoError(err)
+
+	stdLocalThirdPartyContents := `package fixtures
+
+import (
+	"fmt"
+
+	"example.com/project/foo"
+
+	"github.com/other/bar"
+)
+`
+
+	stdThirdPartyLocalContents := `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/other/bar"
+
+	"example.com/project/foo"
+)
+`
+
+	for filePath, contents := range map[string]string{
+		"go.mod":      "module example.com/project\n",
+		"a/a.go":      stdLocalThirdPartyContents,
+		"b/b.go":      stdLocalThirdPartyContents,
+		"c/c.go":      stdThirdPartyLocalContents,
+		"d/d.go":      "package fixtures\n\nimport \"fmt\"\n",
+		"d/d_test.go": stdThirdPartyLocalContents,
+	} {
+		s.Require().NoError(os.MkdirAll(path.Dir(path.Join(s.tempDir, filePath)), 0755))
+		s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, filePath), []byte(contents), 0644))
+	}
+}
+
+func (s *InferTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *InferTestSuite) TestInferSchemes() {
+	schemeConformances, err := InferSchemes([]string{s.tempDir + "/..."}, &VerifyOptions{
+		SkipTests: true,
+	}, 2)
+	s.Require().NoError(err)
+
+	s.Require().Equal([]SchemeConformance{
+		{
+			Scheme:             ImportGroupVerificationSchemeStdLocalThirdParty,
+			LocalPrefix:        "example.com/project",
+			NumFiles:           4,
+			NumConformingFiles: 3,
+		},
+		{
+			Scheme:             ImportGroupVerificationSchemeStdThirdPartyLocal,
+			LocalPrefix:        "example.com/project",
+			NumFiles:           4,
+			NumConformingFiles: 2,
+		},
+	}, schemeConformances)
+
+	s.Require().Equal(75.0, schemeConformances[0].GetPercentage())
+}
+
+func (s *InferTestSuite) TestInferSchemesWithLocalPrefix() {
+	schemeConformances, err := InferSchemes([]string{s.tempDir + "/..."}, &VerifyOptions{
+		LocalPrefix: "github.com/other",
+	}, 2)
+	s.Require().NoError(err)
+
+	for _, schemeConformance := range schemeConformances {
+		s.Require().Equal("github.com/other", schemeConformance.LocalPrefix)
+		s.Require().Equal(5, schemeConformance.NumFiles)
+	}
+}
+
+func (s *InferTestSuite) TestInferSchemesWithConfiguration() {
+	schemeConformances, err := InferSchemes([]string{s.tempDir + "/..."}, &VerifyOptions{
+		Scheme:    ImportGroupVerificationSchemeStdLocalThirdParty,
+		SortOrder: ImportSortOrderAliasFirst,
+		SkipTests: true,
+	}, 2)
+	s.Require().NoError(err)
+
+	// the configured candidate is preferred over the scheme conforming as well on its own
+	s.Require().Len(schemeConformances, 3)
+	s.Require().Equal(SchemeConformance{
+		Scheme:             ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix:        "example.com/project",
+		SortOrder:          ImportSortOrderAliasFirst,
+		NumFiles:           4,
+		NumConformingFiles: 3,
+	}, schemeConformances[0])
+	s.Require().False(schemeConformances[1].IsConfigured())
+}
+
+func TestInferTestSuite(t *testing.T) {
+	suite.Run(t, new(InferTestSuite))
+}
@@ -0,0 +1,146 @@
+package impi
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+)
+
+// Migrate rewrites the imports of the files in the path from one scheme to the scheme of the verification
+// options, keeping their comments and aliases. Files which don't follow the scheme they're migrated from,
+// or whose comments would be misplaced by the rewrite (e.g. comments between groups), are reported and left
+// as they are. Only the options that select files are used, as when inferring schemes. Path follows go tool
+// semantics (e.g. ./...)
+func (i *Impi) Migrate(rootPath string,
+	fromScheme ImportGroupVerificationScheme,
+	verifyOptions *VerifyOptions,
+	errorReporter ErrorReporter) error {
+	if _, err := getVerificationScheme(fromScheme); err != nil {
+		return err
+	}
+
+	return i.run(func() error {
+		return i.populatePathsChan(rootPath)
+	}, verifyOptions, errorReporter, func(verifier *verifier, fixer *fixer, filePath string) error {
+		return i.migrateFile(verifier, fixer, filePath, fromScheme)
+	})
+}
+
+func (i *Impi) migrateFile(verifier *verifier,
+	fixer *fixer,
+	filePath string,
+	fromScheme ImportGroupVerificationScheme) error {
+	source, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	// skip files which wouldn't be built under the constraints
+	match, err := i.matchBuildConstraints(filePath, source)
+	if err != nil || !match {
+		return err
+	}
+
+	fromOptions := getInferenceOptions(i.verifyOptions, &SchemeConformance{
+		Scheme:      fromScheme,
+		LocalPrefix: i.verifyOptions.LocalPrefix,
+	})
+
+	toOptions := getInferenceOptions(i.verifyOptions, &SchemeConformance{
+		Scheme:      i.verifyOptions.Scheme,
+		LocalPrefix: i.verifyOptions.LocalPrefix,
+	})
+
+	// count the file by its last verification
+	defer func() {
+		i.addFileStats(filePath, &verifier.fileStats)
+	}()
+
+	// files which don't follow the scheme they're migrated from may be grouped the way they are on purpose
+	err = verifier.verify(filePath, bytes.NewReader(source), fromOptions)
+	if _, ok := err.(ruleViolations); ok {
+		return ruleViolations{{
+			rule:    ruleMigration,
+			message: fmt.Sprintf("Imports don't follow %s, so they were not migrated", fromScheme),
+		}}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// files which already follow the scheme they're migrated to are left as they are
+	err = verifier.verify(filePath, bytes.NewReader(source), toOptions)
+	if _, ok := err.(ruleViolations); !ok {
+		return err
+	}
+
+	commentLineNum, err := getCommentBetweenGroupsLineNum(source)
+	if err != nil {
+		return err
+	}
+
+	if commentLineNum != 0 {
+		return ruleViolations{{
+			rule:    ruleMigration,
+			lineNum: commentLineNum,
+			message: "Comment between import groups can't be migrated safely, so imports were not migrated",
+		}}
+	}
+
+	fixedSource, err := fixer.fix(filePath, source, toOptions)
+	if err != nil {
+		return ruleViolations{{
+			rule:    ruleMigration,
+			message: fmt.Sprintf("Failed to migrate imports: %s", err.Error()),
+		}}
+	}
+
+	if err := writeFilePreservingMode(filePath, fixedSource); err != nil {
+		return err
+	}
+
+	i.addFixedFile()
+
+	// report whatever the migration did not take care of
+	return verifier.verify(filePath, bytes.NewReader(fixedSource), toOptions)
+}
+
+// getCommentBetweenGroupsLineNum returns the line of the first comment heading a group of imports other
+// than the first - one preceded by an empty line. Such comments usually describe the group (e.g. "// third
+// party"), so moving them along with the import they're attached to would misplace them. 0 is returned if
+// there's no such comment
+func getCommentBetweenGroupsLineNum(source []byte) (int, error) {
+	sourceFileSet := token.NewFileSet()
+
+	sourceNode, err := parser.ParseFile(sourceFileSet, "", source, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return 0, err
+	}
+
+	sourceLines := bytes.Split(source, []byte("\n"))
+
+	for _, decl := range sourceNode.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		for specIndex, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			if specIndex == 0 || importSpec.Doc == nil {
+				continue
+			}
+
+			commentLineNum := sourceFileSet.Position(importSpec.Doc.Pos()).Line
+			if len(bytes.TrimSpace(sourceLines[commentLineNum-2])) == 0 {
+				return commentLineNum, nil
+			}
+		}
+	}
+
+	return 0, nil
+}
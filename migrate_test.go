@@ -0,0 +1,127 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrateTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (s *MigrateTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-migrate")
+	s.Require().NoError(err)
+}
+
+func (s *MigrateTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *MigrateTestSuite) writeFile(fileName string, contents string) {
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, fileName), []byte(contents), 0644))
+}
+
+func (s *MigrateTestSuite) readFile(fileName string) string {
+	contents, err := ioutil.ReadFile(path.Join(s.tempDir, fileName))
+	s.Require().NoError(err)
+
+	return string(contents)
+}
+
+func (s *MigrateTestSuite) TestMigrate() {
+	s.writeFile("migrated.go", `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/other/bar"
+	// baz does things
+	baz "github.com/other/baz"
+
+	"github.com/pavius/impi/foo" // local
+)
+`)
+
+	s.writeFile("unchanged.go", `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/pavius/impi/foo"
+)
+`)
+
+	headedContents := `package fixtures
+
+import (
+	"fmt"
+
+	// third party
+	"github.com/other/bar"
+
+	"github.com/pavius/impi/foo"
+)
+`
+	s.writeFile("headed.go", headedContents)
+
+	nonConformingContents := `package fixtures
+
+import (
+	"github.com/pavius/impi/foo"
+	"fmt"
+)
+`
+	s.writeFile("nonconforming.go", nonConformingContents)
+
+	impi, err := NewImpi(2)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+
+	err = impi.Migrate(s.tempDir, ImportGroupVerificationSchemeStdThirdPartyLocal, &VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}, errorReporter)
+	s.Require().Error(err)
+
+	s.Require().Equal(`package fixtures
+
+import (
+	"fmt"
+
+	"github.com/pavius/impi/foo" // local
+
+	"github.com/other/bar"
+	// baz does things
+	baz "github.com/other/baz"
+)
+`, s.readFile("migrated.go"))
+
+	// files which can't be migrated safely are left as they are
+	s.Require().Equal(headedContents, s.readFile("headed.go"))
+	s.Require().Equal(nonConformingContents, s.readFile("nonconforming.go"))
+
+	reportedErrors := map[string]VerificationError{}
+	for _, verificationError := range errorReporter.verificationErrors {
+		reportedErrors[path.Base(verificationError.FilePath)] = verificationError
+	}
+
+	s.Require().Len(reportedErrors, 2)
+	s.Require().Equal(ruleMigration, reportedErrors["headed.go"].Rule)
+	s.Require().Equal(6, reportedErrors["headed.go"].LineNum)
+	s.Require().Contains(reportedErrors["nonconforming.go"].Error(), "Imports don't follow stdThirdPartyLocal")
+
+	s.Require().Equal(1, impi.GetStats().NumFilesFixed)
+}
+
+func TestMigrateTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuite))
+}
@@ -0,0 +1,273 @@
+package impi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// nestedConfigFileName is the name of configuration files which override the options of the files in their
+// directory subtree
+const nestedConfigFileName = DefaultConfigFileName
+
+// sessionOptionNames are the options which select the files to verify or apply to the session as a whole,
+// which overrides and nested configuration files can't set
+var sessionOptionNames = []string{
+	"skip",
+	"skip-tests",
+	"build-constraints",
+	"disable-gitignore",
+	"include-vendor",
+	"include-nested-modules",
+	"cache-dir",
+	"fail-on",
+	"overrides",
+	"fix",
+}
+
+// optionsResolver resolves the options a file is verified with - the options of the session, overridden by
+// the overrides whose paths match the file and then by the nested configuration files of its directories
+type optionsResolver struct {
+	verifyOptions       *VerifyOptions
+	overridePathRegexes [][]*regexp.Regexp
+	rootDirPath         string
+	lock                sync.Mutex
+
+	// nested configuration file contents by directory, nil if the directory has none
+	nestedConfigs map[string][]byte
+
+	// resolved options by the overrides and nested configuration files applied to them
+	resolvedVerifyOptions map[string]*VerifyOptions
+}
+
+func newOptionsResolver(verifyOptions *VerifyOptions) (*optionsResolver, error) {
+	rootDirPath, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &optionsResolver{
+		verifyOptions:         verifyOptions,
+		rootDirPath:           rootDirPath,
+		nestedConfigs:         map[string][]byte{},
+		resolvedVerifyOptions: map[string]*VerifyOptions{},
+	}
+
+	for overrideIndex, override := range verifyOptions.Overrides {
+		if len(override.Paths) == 0 {
+			return nil, &ConfigError{Err: fmt.Errorf("Override %d has no paths", overrideIndex)}
+		}
+
+		var pathRegexes []*regexp.Regexp
+
+		for _, overridePath := range override.Paths {
+			pathRegex, err := regexp.Compile(overridePath)
+			if err != nil {
+				return nil, &ConfigError{Err: err}
+			}
+
+			pathRegexes = append(pathRegexes, pathRegex)
+		}
+
+		resolver.overridePathRegexes = append(resolver.overridePathRegexes, pathRegexes)
+
+		if err := verifyOverriddenOptionNames(override.Options); err != nil {
+			return nil, &ConfigError{Err: fmt.Errorf("Failed to parse override %d: %s", overrideIndex, err.Error())}
+		}
+
+		// catch invalid overrides up front rather than failing every file they match
+		if _, err := resolver.apply([][]byte{override.Options}); err != nil {
+			return nil, &ConfigError{Err: fmt.Errorf("Failed to parse override %d: %s", overrideIndex, err.Error())}
+		}
+	}
+
+	return resolver, nil
+}
+
+// resolve returns the options the file is verified with. Files to which nothing applies are verified with the
+// options of the session
+func (or *optionsResolver) resolve(filePath string) (*VerifyOptions, error) {
+	var keyParts []string
+	var encodedOverrides [][]byte
+
+	for overrideIndex, pathRegexes := range or.overridePathRegexes {
+		for _, pathRegex := range pathRegexes {
+			if pathRegex.MatchString(filePath) {
+				keyParts = append(keyParts, strconv.Itoa(overrideIndex))
+				encodedOverrides = append(encodedOverrides, or.verifyOptions.Overrides[overrideIndex].Options)
+
+				break
+			}
+		}
+	}
+
+	nestedConfigFilePaths, nestedConfigs, err := or.getNestedConfigs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	keyParts = append(keyParts, nestedConfigFilePaths...)
+	encodedOverrides = append(encodedOverrides, nestedConfigs...)
+
+	if len(keyParts) == 0 {
+		return or.verifyOptions, nil
+	}
+
+	key := strings.Join(keyParts, "\x00")
+
+	or.lock.Lock()
+	defer or.lock.Unlock()
+
+	if resolvedVerifyOptions, found := or.resolvedVerifyOptions[key]; found {
+		return resolvedVerifyOptions, nil
+	}
+
+	resolvedVerifyOptions, err := or.apply(encodedOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	or.resolvedVerifyOptions[key] = resolvedVerifyOptions
+
+	return resolvedVerifyOptions, nil
+}
+
+// apply returns a copy of the options of the session, with the JSON encoded overrides applied in order
+func (or *optionsResolver) apply(encodedOverrides [][]byte) (*VerifyOptions, error) {
+
+	// copy through JSON, since decoding into slices reuses their backing arrays
+	encodedVerifyOptions, err := json.Marshal(or.verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedVerifyOptions := &VerifyOptions{}
+
+	if err := json.Unmarshal(encodedVerifyOptions, resolvedVerifyOptions); err != nil {
+		return nil, err
+	}
+
+	for _, encodedOverride := range encodedOverrides {
+		if err := decodeVerifyOptions(encodedOverride, resolvedVerifyOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	// invalid classes would otherwise fail the file with an obscure error
+	if _, err := getImportSubgroupsByType(resolvedVerifyOptions); err != nil {
+		return nil, err
+	}
+
+	if _, err := getRequiredGroupHeaderTypes(resolvedVerifyOptions); err != nil {
+		return nil, err
+	}
+
+	if err := verifySeverities(resolvedVerifyOptions); err != nil {
+		return nil, err
+	}
+
+	if err := verifyGeneratedMarkers(resolvedVerifyOptions); err != nil {
+		return nil, err
+	}
+
+	return resolvedVerifyOptions, nil
+}
+
+// getNestedConfigs returns the paths and contents of the nested configuration files of the directories of the
+// file, outermost first. Only directories under the working directory are searched, since the configuration
+// file of the working directory itself is the one the session reads unless it's given another
+func (or *optionsResolver) getNestedConfigs(filePath string) ([]string, [][]byte, error) {
+	dirPath, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nestedConfigFilePaths []string
+	var nestedConfigs [][]byte
+
+	for strings.HasPrefix(dirPath, or.rootDirPath+string(filepath.Separator)) {
+		nestedConfig, err := or.getNestedConfig(dirPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if nestedConfig != nil {
+			nestedConfigFilePaths = append([]string{filepath.Join(dirPath, nestedConfigFileName)}, nestedConfigFilePaths...)
+			nestedConfigs = append([][]byte{nestedConfig}, nestedConfigs...)
+		}
+
+		dirPath = filepath.Dir(dirPath)
+	}
+
+	return nestedConfigFilePaths, nestedConfigs, nil
+}
+
+// getNestedConfig returns the contents of the nested configuration file of the directory, or nil if it has none
+func (or *optionsResolver) getNestedConfig(dirPath string) ([]byte, error) {
+	or.lock.Lock()
+	defer or.lock.Unlock()
+
+	if nestedConfig, found := or.nestedConfigs[dirPath]; found {
+		return nestedConfig, nil
+	}
+
+	nestedConfigFilePath := filepath.Join(dirPath, nestedConfigFileName)
+
+	nestedConfig, err := ioutil.ReadFile(nestedConfigFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if nestedConfig != nil {
+
+		// validate the file on its own, so that errors name it
+		if err := decodeVerifyOptions(nestedConfig, &VerifyOptions{}); err != nil {
+			return nil, fmt.Errorf("Failed to parse configuration file %s: %s", nestedConfigFilePath, err.Error())
+		}
+
+		if err := verifyOverriddenOptionNames(nestedConfig); err != nil {
+			return nil, fmt.Errorf("Failed to parse configuration file %s: %s", nestedConfigFilePath, err.Error())
+		}
+	}
+
+	or.nestedConfigs[dirPath] = nestedConfig
+
+	return nestedConfig, nil
+}
+
+// verifyOverriddenOptionNames verifies that JSON encoded overrides set no session options, which would
+// otherwise be silently ignored
+func verifyOverriddenOptionNames(encodedOverride []byte) error {
+	var encodedOptionsByName map[string]json.RawMessage
+
+	if err := json.Unmarshal(encodedOverride, &encodedOptionsByName); err != nil {
+		return err
+	}
+
+	for _, sessionOptionName := range sessionOptionNames {
+		if _, found := encodedOptionsByName[sessionOptionName]; found {
+			return fmt.Errorf("%s can only be set in the root configuration", sessionOptionName)
+		}
+	}
+
+	// test files are checked or reported per file, but skipped by the session
+	if encodedTestFilesPolicy, found := encodedOptionsByName["tests"]; found {
+		var testFilesPolicy TestFilesPolicy
+
+		if err := json.Unmarshal(encodedTestFilesPolicy, &testFilesPolicy); err != nil {
+			return err
+		}
+
+		if testFilesPolicy == TestFilesPolicySkip {
+			return fmt.Errorf("tests: %s can only be set in the root configuration", testFilesPolicy)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package impi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OverridesTestSuite struct {
+	suite.Suite
+	tempDir string
+	options VerifyOptions
+}
+
+func (s *OverridesTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-overrides")
+	s.Require().NoError(err)
+
+	s.options = VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}
+}
+
+func (s *OverridesTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *OverridesTestSuite) TestPathOverrides() {
+	contents := `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/other/bar"
+
+	"github.com/pavius/impi/foo"
+)
+`
+
+	for _, filePath := range []string{"svc/a.go", "legacy/b.go", "legacy/c_test.go"} {
+		s.writeFile(filePath, contents)
+	}
+
+	s.options.Overrides = []VerifyOptionsOverride{
+		{
+			Paths:   []string{"/legacy/"},
+			Options: json.RawMessage(`{"scheme": "stdThirdPartyLocal", "forbid-trailing-comments": true}`),
+		},
+		{
+			Paths:   []string{"_test\\.go$"},
+			Options: json.RawMessage(`{"scheme": "stdLocalThirdParty"}`),
+		},
+	}
+
+	impi, err := NewImpi(2)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+
+	s.Require().Error(impi.Verify(path.Join(s.tempDir, "..."), &s.options, errorReporter))
+
+	// later overrides take precedence
+	var failedFilePaths []string
+
+	for _, verificationError := range errorReporter.verificationErrors {
+		failedFilePaths = append(failedFilePaths, verificationError.FilePath)
+	}
+
+	sort.Strings(failedFilePaths)
+
+	s.Require().Equal([]string{
+		path.Join(s.tempDir, "legacy/c_test.go"),
+		path.Join(s.tempDir, "svc/a.go"),
+	}, failedFilePaths)
+}
+
+func (s *OverridesTestSuite) TestNestedConfigs() {
+	s.writeFile("legacy/.impi.json", `{"scheme": "stdThirdPartyLocal"}`)
+	s.writeFile("legacy/strict/.impi.json", `{"local": "github.com/pavius/impi/legacy"}`)
+
+	s.options.Overrides = []VerifyOptionsOverride{
+		{
+			Paths:   []string{"/legacy/"},
+			Options: json.RawMessage(`{"scheme": "stdLocalThirdParty", "forbid-dot-imports": true}`),
+		},
+	}
+
+	resolver, err := newOptionsResolver(&s.options)
+	s.Require().NoError(err)
+
+	resolver.rootDirPath = s.tempDir
+
+	// files without overrides are verified with the options of the session
+	verifyOptions, err := resolver.resolve(path.Join(s.tempDir, "svc/a.go"))
+	s.Require().NoError(err)
+	s.Require().Equal(&s.options, verifyOptions)
+
+	// nested configuration files apply after the overrides, outermost first
+	verifyOptions, err = resolver.resolve(path.Join(s.tempDir, "legacy/strict/a.go"))
+	s.Require().NoError(err)
+	s.Require().Equal(ImportGroupVerificationSchemeStdThirdPartyLocal, verifyOptions.Scheme)
+	s.Require().Equal("github.com/pavius/impi/legacy", verifyOptions.LocalPrefix)
+	s.Require().True(verifyOptions.ForbidDotImports)
+
+	// files in the same directory share their options
+	otherVerifyOptions, err := resolver.resolve(path.Join(s.tempDir, "legacy/strict/b.go"))
+	s.Require().NoError(err)
+	s.Require().True(verifyOptions == otherVerifyOptions)
+
+	// the options of the session are left as they are
+	s.Require().Equal(ImportGroupVerificationSchemeStdLocalThirdParty, s.options.Scheme)
+	s.Require().False(s.options.ForbidDotImports)
+}
+
+func (s *OverridesTestSuite) TestInvalidOverrides() {
+	for _, override := range []VerifyOptionsOverride{
+		{Options: json.RawMessage(`{"scheme": "stdThirdPartyLocal"}`)},
+		{Paths: []string{"("}, Options: json.RawMessage(`{"scheme": "stdThirdPartyLocal"}`)},
+		{Paths: []string{"legacy"}, Options: json.RawMessage(`{"shceme": "stdThirdPartyLocal"}`)},
+		{Paths: []string{"legacy"}, Options: json.RawMessage(`{"require-group-headers": ["other"]}`)},
+		{Paths: []string{"legacy"}, Options: json.RawMessage(`{"skip": ["^gen/"]}`)},
+		{Paths: []string{"legacy"}, Options: json.RawMessage(`{"tests": "skip"}`)},
+		{Paths: []string{"legacy"}, Options: json.RawMessage(`{"fix": true}`)},
+	} {
+		s.options.Overrides = []VerifyOptionsOverride{override}
+
+		_, err := newOptionsResolver(&s.options)
+		s.Require().Error(err, string(override.Options))
+	}
+
+	s.options.Overrides = nil
+	s.writeFile("legacy/.impi.json", `{"shceme": "stdThirdPartyLocal"}`)
+
+	resolver, err := newOptionsResolver(&s.options)
+	s.Require().NoError(err)
+
+	resolver.rootDirPath = s.tempDir
+
+	_, err = resolver.resolve(path.Join(s.tempDir, "legacy/a.go"))
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), path.Join(s.tempDir, "legacy/.impi.json"))
+
+	// session options can't be set by nested configuration files either
+	s.writeFile("other/.impi.json", `{"cache-dir": "/tmp/impi"}`)
+
+	_, err = resolver.resolve(path.Join(s.tempDir, "other/a.go"))
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "cache-dir can only be set in the root configuration")
+
+	// test files may be checked or reported per directory
+	s.writeFile("tests/.impi.json", `{"tests": "report-only"}`)
+
+	verifyOptions, err := resolver.resolve(path.Join(s.tempDir, "tests/a.go"))
+	s.Require().NoError(err)
+	s.Require().Equal(TestFilesPolicyReportOnly, verifyOptions.Tests)
+}
+
+func (s *OverridesTestSuite) writeFile(filePath string, contents string) {
+	s.Require().NoError(os.MkdirAll(path.Dir(path.Join(s.tempDir, filePath)), 0755))
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, filePath), []byte(contents), 0644))
+}
+
+func TestOverridesTestSuite(t *testing.T) {
+	suite.Run(t, new(OverridesTestSuite))
+}
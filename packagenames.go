@@ -0,0 +1,82 @@
+package impi
+
+import (
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// getPackageName returns the name the package of the import path declares, if the package can be resolved -
+// that is, if it belongs to the module of the file being verified or is under GOPATH. An empty string is
+// returned otherwise
+func (v *verifier) getPackageName(importPath string) string {
+	packageDirPath := getPackageDir(filepath.Dir(v.filePath), importPath)
+	if packageDirPath == "" {
+		return ""
+	}
+
+	if packageName, found := v.packageNames[packageDirPath]; found {
+		return packageName
+	}
+
+	packageName := readPackageName(packageDirPath)
+	v.packageNames[packageDirPath] = packageName
+
+	return packageName
+}
+
+// getPackageDir returns the directory of the package of the import path, as seen from the directory - either in
+// the module of the directory or under GOPATH. An empty string is returned if it's neither
+func getPackageDir(dirPath string, importPath string) string {
+	if module, err := findGoModule(dirPath); err == nil && module != nil {
+		if importPath == module.path {
+			return module.rootDir
+		}
+
+		if strings.HasPrefix(importPath, module.path+"/") {
+			return filepath.Join(module.rootDir, filepath.FromSlash(strings.TrimPrefix(importPath, module.path+"/")))
+		}
+	}
+
+	for _, goPath := range filepath.SplitList(build.Default.GOPATH) {
+		packageDirPath := filepath.Join(goPath, "src", filepath.FromSlash(importPath))
+
+		if isDir(packageDirPath) {
+			return packageDirPath
+		}
+	}
+
+	return ""
+}
+
+// readPackageName returns the package name declared by the non-test go files of the directory, or an empty
+// string if there are none which parse
+func readPackageName(packageDirPath string) string {
+	fileInfos, err := ioutil.ReadDir(packageDirPath)
+	if err != nil {
+		return ""
+	}
+
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() || !strings.HasSuffix(fileInfo.Name(), ".go") || isTestFilePath(fileInfo.Name()) {
+			continue
+		}
+
+		sourceNode, err := parser.ParseFile(token.NewFileSet(),
+			filepath.Join(packageDirPath, fileInfo.Name()),
+			nil,
+			parser.PackageClauseOnly)
+
+		// main packages and tools (e.g. those tagged ignore) don't tell the name of the package
+		if err != nil || sourceNode.Name.Name == "main" {
+			continue
+		}
+
+		return sourceNode.Name.Name
+	}
+
+	return ""
+}
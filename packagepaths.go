@@ -0,0 +1,203 @@
+package impi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// getPackagePaths returns the paths of the packages (directories) or files the root path specifies. Root
+// paths follow go tool semantics: a file, a directory, a directory followed by "/..." for all packages under
+// it, or an import path within the module of the working directory. Walking packages under a directory skips
+// testdata, directories starting with "." or "_", vendor directories and nested modules, unless specified
+// otherwise in the verification options
+func (i *Impi) getPackagePaths(rootPath string) ([]string, error) {
+	dirPath, recursive, err := resolveRootPath(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// files and directories are taken as is
+	if !recursive || !isDir(dirPath) {
+		return []string{dirPath}, nil
+	}
+
+	var packagePaths []string
+
+	err = i.walkDirs(dirPath, func(walkedPath string) error {
+		containsGoFiles, err := dirContainsGoFiles(walkedPath)
+		if err != nil {
+			return err
+		}
+
+		if containsGoFiles {
+			packagePaths = append(packagePaths, walkedPath)
+		}
+
+		return nil
+	})
+
+	return packagePaths, err
+}
+
+// getDirPaths returns the directories in which the root path's packages may reside - all the directories
+// walked for a recursive root path, whether they contain go files or not, or the directory of a file
+func (i *Impi) getDirPaths(rootPath string) ([]string, error) {
+	dirPath, recursive, err := resolveRootPath(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isDir(dirPath) {
+		return []string{filepath.Dir(dirPath)}, nil
+	}
+
+	if !recursive {
+		return []string{dirPath}, nil
+	}
+
+	var dirPaths []string
+
+	err = i.walkDirs(dirPath, func(walkedPath string) error {
+		dirPaths = append(dirPaths, walkedPath)
+		return nil
+	})
+
+	return dirPaths, err
+}
+
+// resolveRootPath returns the file or directory a root path refers to, and whether it refers to all the
+// packages under it
+func resolveRootPath(rootPath string) (string, bool, error) {
+	recursive := rootPath == "..." || strings.HasSuffix(rootPath, "/...")
+
+	dirPath := strings.TrimSuffix(strings.TrimSuffix(rootPath, "..."), "/")
+	if dirPath == "" {
+		dirPath = "."
+	}
+
+	if _, err := os.Stat(dirPath); err != nil {
+
+		// it's not a path in the file system, so it may be an import path within the module
+		dirPath, err = getImportPathDir(dirPath)
+		if err != nil {
+			return "", false, err
+		}
+
+		if _, err = os.Stat(dirPath); err != nil {
+			return "", false, &ConfigError{Err: fmt.Errorf("Could not find packages in %s", rootPath)}
+		}
+	}
+
+	return dirPath, recursive, nil
+}
+
+// walkDirs calls walkFunc for the directory and every directory under it which isn't skipped
+func (i *Impi) walkDirs(dirPath string, walkFunc func(walkedPath string) error) error {
+	return filepath.Walk(dirPath, func(walkedPath string, walkedFileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !walkedFileInfo.IsDir() {
+			return nil
+		}
+
+		if walkedPath != dirPath {
+			skip, err := i.skipDir(walkedPath, walkedFileInfo.Name())
+			if err != nil {
+				return err
+			}
+
+			if skip {
+				return filepath.SkipDir
+			}
+		}
+
+		return walkFunc(walkedPath)
+	})
+}
+
+// skipDir returns whether a directory should be skipped when walking packages
+func (i *Impi) skipDir(dirPath string, dirName string) (bool, error) {
+	switch {
+	case strings.HasPrefix(dirName, ".") || strings.HasPrefix(dirName, "_") || dirName == "testdata":
+		return true, nil
+	case dirName == "vendor" && !i.verifyOptions.IncludeVendor:
+		return true, nil
+	}
+
+	// directories with a go.mod belong to a different module
+	if _, err := os.Stat(filepath.Join(dirPath, "go.mod")); err == nil && !i.verifyOptions.IncludeNestedModules {
+		return true, nil
+	}
+
+	return i.ignoreMatcher.isIgnored(dirPath, true)
+}
+
+// isUnderSkippedDir returns whether any of the directories of the file, under the working directory, would be
+// skipped when walking packages
+func (i *Impi) isUnderSkippedDir(filePath string) (bool, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return false, err
+	}
+
+	workingDirPath, err := os.Getwd()
+	if err != nil {
+		return false, err
+	}
+
+	relFilePath, err := filepath.Rel(workingDirPath, absFilePath)
+	if err != nil || !isPathUnderDir(absFilePath, workingDirPath) {
+		return false, nil
+	}
+
+	dirPath := ""
+
+	for _, dirName := range strings.Split(filepath.Dir(relFilePath), string(filepath.Separator)) {
+		if dirName == "." {
+			continue
+		}
+
+		dirPath = filepath.Join(dirPath, dirName)
+
+		skip, err := i.skipDir(dirPath, dirName)
+		if err != nil || skip {
+			return skip, err
+		}
+	}
+
+	return false, nil
+}
+
+// getImportPathDir returns the directory of a package of the working directory's module, given its import path
+func getImportPathDir(importPath string) (string, error) {
+	module, err := findGoModule(".")
+	if err != nil {
+		return "", err
+	}
+
+	if module == nil || !matchImportPathPrefixes([]string{module.path}, importPath) {
+		return "", &ConfigError{Err: fmt.Errorf("Could not find packages in %s", importPath)}
+	}
+
+	return filepath.Join(module.rootDir, filepath.FromSlash(getModuleRelPath(module, importPath))), nil
+}
+
+func dirContainsGoFiles(dirPath string) (bool, error) {
+	fileInfos, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return false, err
+	}
+
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() && strings.HasSuffix(fileInfo.Name(), ".go") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,153 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PackagePathsTestSuite struct {
+	suite.Suite
+	tempDir string
+	impi    *Impi
+}
+
+func (s *PackagePathsTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-packages")
+	s.Require().NoError(err)
+
+	for _, filePath := range []string{
+		"go.mod",
+		"main.go",
+		"pkg/a/a.go",
+		"pkg/a/testdata/fixture.go",
+		"pkg/b/README.md",
+		"pkg/b/c/c.go",
+		"vendor/github.com/foo/bar/bar.go",
+		"nested/go.mod",
+		"nested/nested.go",
+		".hidden/hidden.go",
+		"_skipped/skipped.go",
+	} {
+		s.Require().NoError(os.MkdirAll(path.Dir(path.Join(s.tempDir, filePath)), 0755))
+		s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, filePath), []byte{}, 0644))
+	}
+
+	s.impi, err = NewImpi(1)
+	s.Require().NoError(err)
+
+	s.impi.verifyOptions = &VerifyOptions{}
+	s.impi.ignoreMatcher = newIgnoreMatcher([]string{impiIgnoreFileName})
+}
+
+func (s *PackagePathsTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *PackagePathsTestSuite) TestRecursive() {
+	packagePaths, err := s.impi.getPackagePaths(s.tempDir + "/...")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{
+		s.tempDir,
+		path.Join(s.tempDir, "pkg/a"),
+		path.Join(s.tempDir, "pkg/b/c"),
+	}, packagePaths)
+}
+
+func (s *PackagePathsTestSuite) TestRecursiveIncludingVendorAndNestedModules() {
+	s.impi.verifyOptions.IncludeVendor = true
+	s.impi.verifyOptions.IncludeNestedModules = true
+
+	packagePaths, err := s.impi.getPackagePaths(s.tempDir + "/...")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{
+		s.tempDir,
+		path.Join(s.tempDir, "nested"),
+		path.Join(s.tempDir, "pkg/a"),
+		path.Join(s.tempDir, "pkg/b/c"),
+		path.Join(s.tempDir, "vendor/github.com/foo/bar"),
+	}, packagePaths)
+}
+
+func (s *PackagePathsTestSuite) TestDirAndFile() {
+	packagePaths, err := s.impi.getPackagePaths(path.Join(s.tempDir, "pkg/a"))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{path.Join(s.tempDir, "pkg/a")}, packagePaths)
+
+	packagePaths, err = s.impi.getPackagePaths(path.Join(s.tempDir, "main.go"))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{path.Join(s.tempDir, "main.go")}, packagePaths)
+
+	// paths which don't exist are errors in what to verify rather than failures to verify it
+	_, err = s.impi.getPackagePaths(path.Join(s.tempDir, "nothing"))
+	s.Require().IsType(&ConfigError{}, err)
+}
+
+func (s *PackagePathsTestSuite) TestVerifyFiles() {
+	workingDirPath, err := os.Getwd()
+	s.Require().NoError(err)
+
+	s.Require().NoError(os.Chdir(s.tempDir))
+	defer os.Chdir(workingDirPath)
+
+	var readFilePaths []string
+
+	readFile := func(filePath string) ([]byte, error) {
+		readFilePaths = append(readFilePaths, filePath)
+		return []byte("package fixtures\n"), nil
+	}
+
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	verifyOptions := &VerifyOptions{Scheme: ImportGroupVerificationSchemeStdLocalThirdParty}
+
+	// files under directories which walking skips are skipped as well
+	s.Require().NoError(impi.VerifyFiles([]string{
+		"main.go",
+		"pkg/a/a.go",
+		"pkg/a/testdata/fixture.go",
+		"pkg/b/README.md",
+		"vendor/github.com/foo/bar/bar.go",
+		"nested/nested.go",
+		".hidden/hidden.go",
+		"_skipped/skipped.go",
+	}, readFile, verifyOptions, &collectingErrorReporter{}))
+
+	s.Require().Equal([]string{"main.go", "pkg/a/a.go"}, readFilePaths)
+	s.Require().Equal(2, impi.GetStats().NumFilesScanned)
+
+	// files which aren't read from disk can't be fixed
+	impi, err = NewImpi(1)
+	s.Require().NoError(err)
+
+	verifyOptions.Fix = true
+
+	err = impi.VerifyFiles([]string{"main.go"}, readFile, verifyOptions, &collectingErrorReporter{})
+	s.Require().IsType(&ConfigError{}, err)
+}
+
+func (s *PackagePathsTestSuite) TestDirPaths() {
+	dirPaths, err := s.impi.getDirPaths(s.tempDir + "/...")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{
+		s.tempDir,
+		path.Join(s.tempDir, "pkg"),
+		path.Join(s.tempDir, "pkg/a"),
+		path.Join(s.tempDir, "pkg/b"),
+		path.Join(s.tempDir, "pkg/b/c"),
+	}, dirPaths)
+
+	dirPaths, err = s.impi.getDirPaths(path.Join(s.tempDir, "pkg/a/a.go"))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{path.Join(s.tempDir, "pkg/a")}, dirPaths)
+}
+
+func TestPackagePathsTestSuite(t *testing.T) {
+	suite.Run(t, new(PackagePathsTestSuite))
+}
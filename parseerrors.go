@@ -0,0 +1,71 @@
+package impi
+
+import (
+	"go/ast"
+	"go/scanner"
+	"go/token"
+)
+
+// getParseViolation returns the violation reporting the first syntax error of a file which failed to
+// parse, or nil if the error isn't a syntax error
+func getParseViolation(err error) *ruleViolation {
+	errorList, ok := err.(scanner.ErrorList)
+	if !ok || len(errorList) == 0 {
+		return nil
+	}
+
+	return &ruleViolation{
+		rule:    ruleParseError,
+		lineNum: errorList[0].Pos.Line,
+		column:  errorList[0].Pos.Column,
+		message: errorList[0].Msg,
+	}
+}
+
+// isImportDeclsParsed returns whether the import declarations of a file which failed to parse were parsed
+// in full before the first syntax error, so that the imports can be verified from the partial result
+func isImportDeclsParsed(sourceFileSet *token.FileSet, sourceNode *ast.File, err error) bool {
+	errorList, ok := err.(scanner.ErrorList)
+	if !ok || len(errorList) == 0 || sourceNode == nil || len(sourceNode.Imports) == 0 {
+		return false
+	}
+
+	lastImportDeclEnd := token.NoPos
+
+	for _, decl := range sourceNode.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			lastImportDeclEnd = genDecl.End()
+		}
+	}
+
+	// declarations cut short by the error end at an unknown position
+	lastImportDeclEndPosition := sourceFileSet.Position(lastImportDeclEnd)
+	if !lastImportDeclEndPosition.IsValid() {
+		return false
+	}
+
+	return errorList[0].Pos.Offset >= lastImportDeclEndPosition.Offset
+}
+
+// getFindingKind returns the kind of finding the violation is reported as
+func (rv *ruleViolation) getFindingKind() string {
+	switch rv.rule {
+	case ruleParseError:
+		return FindingKindParseError
+	case "":
+		return FindingKindFailure
+	default:
+		return FindingKindViolation
+	}
+}
+
+// hasParseViolation returns whether the file failed to parse, in which case it can't be fixed
+func (rvs ruleViolations) hasParseViolation() bool {
+	for _, violation := range rvs {
+		if violation.rule == ruleParseError {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ParseErrorsTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *ParseErrorsTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+}
+
+func (s *ParseErrorsTestSuite) TestUnparseableImports() {
+	err := s.verify(`package fixtures
+
+import (
+	"os"
+	"fmt
+)
+`)
+	s.Require().Error(err)
+
+	// nothing is verified when the imports themselves don't parse
+	violations := err.(ruleViolations)
+	s.Require().Len(violations, 1)
+	s.Require().Equal(ruleParseError, violations[0].rule)
+	s.Require().Equal(5, violations[0].lineNum)
+	s.Require().Equal(2, violations[0].column)
+	s.Require().Equal(FindingKindParseError, violations[0].getFindingKind())
+}
+
+func (s *ParseErrorsTestSuite) TestPartialParse() {
+	err := s.verify(`package fixtures
+
+import (
+	"os"
+	"fmt"
+) garbage
+`)
+	s.Require().Error(err)
+
+	// the imports parsed in full, so they're verified
+	violations := err.(ruleViolations)
+	s.Require().Len(violations, 2)
+	s.Require().Equal(ruleParseError, violations[0].rule)
+	s.Require().Equal(6, violations[0].lineNum)
+	s.Require().Equal(ruleImportGroups, violations[1].rule)
+
+	s.options.SkipUnparseable = true
+	defer func() { s.options.SkipUnparseable = false }()
+
+	s.Require().NoError(s.verify(`package fixtures
+
+import (
+	"fmt"
+	"os"
+) garbage
+`))
+	s.Require().Equal(SkipReasonUnparseable, s.verifier.fileStats.skipReason)
+}
+
+func (s *ParseErrorsTestSuite) TestFailures() {
+	tempDir, err := ioutil.TempDir("", "impi-parseerrors")
+	s.Require().NoError(err)
+
+	defer os.RemoveAll(tempDir)
+
+	filePath := path.Join(tempDir, "a.go")
+	contents := "packge fixtures\n"
+
+	s.Require().NoError(ioutil.WriteFile(filePath, []byte(contents), 0644))
+
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+	options := s.options
+	options.Fix = true
+
+	s.Require().Error(impi.Verify(filePath, &options, errorReporter))
+	s.Require().Len(errorReporter.verificationErrors, 1)
+	s.Require().Equal(FindingKindParseError, errorReporter.verificationErrors[0].Kind)
+	s.Require().Equal(1, errorReporter.verificationErrors[0].LineNum)
+	s.Require().Equal(1, errorReporter.verificationErrors[0].Column)
+
+	// parse errors are failures rather than violations, and files which don't parse aren't fixed
+	stats := impi.GetStats()
+	s.Require().Equal(1, stats.NumFailures)
+	s.Require().Empty(stats.NumViolationsByRule)
+	s.Require().Equal(0, stats.NumFilesFixed)
+
+	fixedContents, err := ioutil.ReadFile(filePath)
+	s.Require().NoError(err)
+	s.Require().Equal(contents, string(fixedContents))
+}
+
+func TestParseErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseErrorsTestSuite))
+}
@@ -0,0 +1,57 @@
+package impi
+
+import (
+	"fmt"
+)
+
+// verifySeverities verifies that severities are only assigned to known rules, and that findings of some
+// severity can fail verification
+func verifySeverities(verifyOptions *VerifyOptions) error {
+	for rule := range verifyOptions.Severities {
+		if !isKnownRule(rule) {
+			return fmt.Errorf("Unknown rule: %s", rule)
+		}
+	}
+
+	if verifyOptions.FailOn == SeverityOff {
+		return fmt.Errorf("Unsupported fail-on severity: %s", verifyOptions.FailOn)
+	}
+
+	return nil
+}
+
+// getSeverity returns the severity of the findings of a rule. Errors which aren't raised by a rule are
+// always of SeverityError
+func (vo *VerifyOptions) getSeverity(rule string) Severity {
+	if rule == "" {
+		return SeverityError
+	}
+
+	return vo.Severities[rule]
+}
+
+// applySeverities sets the severity of each violation by its rule, dropping violations of rules which
+// are turned off
+func applySeverities(violations ruleViolations, verifyOptions *VerifyOptions) ruleViolations {
+	var reportedViolations ruleViolations
+
+	for _, violation := range violations {
+		violation.severity = verifyOptions.getSeverity(violation.rule)
+
+		if violation.severity != SeverityOff {
+			reportedViolations = append(reportedViolations, violation)
+		}
+	}
+
+	return reportedViolations
+}
+
+func isKnownRule(rule string) bool {
+	for _, ruleName := range ruleNames {
+		if rule == ruleName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+package impi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SeverityTestSuite struct {
+	suite.Suite
+	tempDir string
+	options VerifyOptions
+}
+
+func (s *SeverityTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-severity")
+	s.Require().NoError(err)
+
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "a.go"), []byte(`package fixtures
+
+import (
+	. "fmt"
+	"os"
+	"net/http"
+)
+`), 0644))
+
+	s.options = VerifyOptions{
+		Scheme:           ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix:      "github.com/pavius/impi",
+		ForbidDotImports: true,
+	}
+}
+
+func (s *SeverityTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *SeverityTestSuite) TestSeverities() {
+	s.options.Severities = map[string]Severity{
+		ruleDotImport:    SeverityWarning,
+		ruleImportGroups: SeverityOff,
+	}
+
+	errorReporter, err := s.verify()
+	s.Require().NoError(err)
+
+	// findings of rules which are turned off aren't reported
+	s.Require().Len(errorReporter.verificationErrors, 1)
+	s.Require().Equal(ruleDotImport, errorReporter.verificationErrors[0].Rule)
+	s.Require().Equal(SeverityWarning, errorReporter.verificationErrors[0].Severity)
+
+	s.options.FailOn = SeverityWarning
+
+	_, err = s.verify()
+	s.Require().Equal(&ViolationsError{NumErrors: 1}, err)
+}
+
+func (s *SeverityTestSuite) TestOverrides() {
+	s.options.Severities = map[string]Severity{ruleDotImport: SeverityInfo}
+	s.options.Overrides = []VerifyOptionsOverride{
+		{
+			Paths:   []string{"a\\.go$"},
+			Options: json.RawMessage(`{"severities": {"import-groups": "warning"}}`),
+		},
+	}
+
+	errorReporter, err := s.verify()
+	s.Require().NoError(err)
+
+	// severities of overrides are merged into those of the session
+	s.Require().Len(errorReporter.verificationErrors, 2)
+
+	for _, verificationError := range errorReporter.verificationErrors {
+		expectedSeverity := SeverityInfo
+		if verificationError.Rule == ruleImportGroups {
+			expectedSeverity = SeverityWarning
+		}
+
+		s.Require().Equal(expectedSeverity, verificationError.Severity)
+	}
+}
+
+func (s *SeverityTestSuite) TestImportBoundaries() {
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "go.mod"),
+		[]byte("module github.com/pavius/impi\n"), 0644))
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "a.go"), []byte(`package fixtures
+
+import (
+	"github.com/pavius/impi/pkg/storage"
+)
+`), 0644))
+
+	s.options.Severities = map[string]Severity{ruleImportBoundary: SeverityWarning}
+	s.options.ImportBoundaries = []ImportBoundary{
+		{
+			Name:     "root-no-storage",
+			Packages: []string{"."},
+			Deny:     []string{"pkg/storage/..."},
+		},
+	}
+
+	errorReporter, err := s.verify()
+	s.Require().NoError(err)
+
+	// named boundaries are subject to the severity of their rule
+	s.Require().Len(errorReporter.verificationErrors, 1)
+	s.Require().Equal(ruleImportBoundary, errorReporter.verificationErrors[0].Rule)
+	s.Require().Equal(SeverityWarning, errorReporter.verificationErrors[0].Severity)
+	s.Require().Contains(errorReporter.verificationErrors[0].Error(), "(root-no-storage)")
+}
+
+func (s *SeverityTestSuite) TestInvalidSeverities() {
+	s.options.Severities = map[string]Severity{"dot-imports": SeverityWarning}
+
+	_, err := s.verify()
+	s.Require().EqualError(err, "Unknown rule: dot-imports")
+
+	s.options.Severities = nil
+	s.options.FailOn = SeverityOff
+
+	_, err = s.verify()
+	s.Require().Error(err)
+
+	var severity Severity
+	s.Require().Error(severity.Set("fatal"))
+}
+
+func (s *SeverityTestSuite) verify() (*collectingErrorReporter, error) {
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	errorReporter := &collectingErrorReporter{}
+
+	return errorReporter, impi.Verify(path.Join(s.tempDir, "a.go"), &s.options, errorReporter)
+}
+
+func TestSeverityTestSuite(t *testing.T) {
+	suite.Run(t, new(SeverityTestSuite))
+}
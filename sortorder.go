@@ -0,0 +1,67 @@
+package impi
+
+import (
+	"strings"
+)
+
+// importSortKey is what imports are sorted by
+type importSortKey struct {
+	name string
+	path string
+}
+
+// less returns whether the first import precedes the second in the sort order. Orders which don't tell
+// two imports apart fall back to sorting them by their path
+func (iso ImportSortOrder) less(first importSortKey, second importSortKey) bool {
+	switch iso {
+	case ImportSortOrderGoimports:
+		if first.path == second.path {
+			return first.name < second.name
+		}
+
+	case ImportSortOrderCaseInsensitive:
+		if lowerFirstPath, lowerSecondPath := strings.ToLower(first.path), strings.ToLower(second.path); lowerFirstPath != lowerSecondPath {
+			return lowerFirstPath < lowerSecondPath
+		}
+
+	case ImportSortOrderParentFirst:
+		return lessPathElements(strings.Split(first.path, "/"), strings.Split(second.path, "/"))
+
+	case ImportSortOrderAlias:
+		if firstName, secondName := first.getReferredName(), second.getReferredName(); firstName != secondName {
+			return firstName < secondName
+		}
+
+	case ImportSortOrderAliasFirst:
+		if firstAliased, secondAliased := first.isAliased(), second.isAliased(); firstAliased != secondAliased {
+			return firstAliased
+		}
+	}
+
+	return first.path < second.path
+}
+
+// isAliased returns whether the import is named, other than blank and dot imports
+func (isk *importSortKey) isAliased() bool {
+	return isk.name != "" && isk.name != "_" && isk.name != "."
+}
+
+// getReferredName returns the name the import is referred by - its alias, or the last element of its path
+func (isk *importSortKey) getReferredName() string {
+	if isk.isAliased() {
+		return isk.name
+	}
+
+	return getLastImportPathElement(isk.path)
+}
+
+// lessPathElements compares paths element by element, so that a path precedes the paths under it
+func lessPathElements(firstElements []string, secondElements []string) bool {
+	for elementIndex := 0; elementIndex < len(firstElements) && elementIndex < len(secondElements); elementIndex++ {
+		if firstElements[elementIndex] != secondElements[elementIndex] {
+			return firstElements[elementIndex] < secondElements[elementIndex]
+		}
+	}
+
+	return len(firstElements) < len(secondElements)
+}
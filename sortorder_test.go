@@ -0,0 +1,153 @@
+package impi
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SortOrderTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *SortOrderTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+}
+
+func (s *SortOrderTestSuite) sort(sortOrder ImportSortOrder, importSortKeys []importSortKey) []importSortKey {
+	sortedImportSortKeys := append([]importSortKey{}, importSortKeys...)
+
+	sort.SliceStable(sortedImportSortKeys, func(first, second int) bool {
+		return sortOrder.less(sortedImportSortKeys[first], sortedImportSortKeys[second])
+	})
+
+	return sortedImportSortKeys
+}
+
+func (s *SortOrderTestSuite) TestLess() {
+	importSortKeys := []importSortKey{
+		{path: "github.com/foo/bar-baz"},
+		{name: "zbar", path: "github.com/foo/bar"},
+		{path: "github.com/Foo/qux"},
+		{path: "github.com/foo/bar/baz"},
+		{name: "abar", path: "github.com/foo/bar"},
+	}
+
+	for _, testCase := range []struct {
+		sortOrder             ImportSortOrder
+		expectedImportSortKey []importSortKey
+	}{
+		{
+			sortOrder: ImportSortOrderLexical,
+			expectedImportSortKey: []importSortKey{
+				{path: "github.com/Foo/qux"},
+				{name: "zbar", path: "github.com/foo/bar"},
+				{name: "abar", path: "github.com/foo/bar"},
+				{path: "github.com/foo/bar-baz"},
+				{path: "github.com/foo/bar/baz"},
+			},
+		},
+		{
+			sortOrder: ImportSortOrderGoimports,
+			expectedImportSortKey: []importSortKey{
+				{path: "github.com/Foo/qux"},
+				{name: "abar", path: "github.com/foo/bar"},
+				{name: "zbar", path: "github.com/foo/bar"},
+				{path: "github.com/foo/bar-baz"},
+				{path: "github.com/foo/bar/baz"},
+			},
+		},
+		{
+			sortOrder: ImportSortOrderCaseInsensitive,
+			expectedImportSortKey: []importSortKey{
+				{name: "zbar", path: "github.com/foo/bar"},
+				{name: "abar", path: "github.com/foo/bar"},
+				{path: "github.com/foo/bar-baz"},
+				{path: "github.com/foo/bar/baz"},
+				{path: "github.com/Foo/qux"},
+			},
+		},
+		{
+			sortOrder: ImportSortOrderParentFirst,
+			expectedImportSortKey: []importSortKey{
+				{path: "github.com/Foo/qux"},
+				{name: "zbar", path: "github.com/foo/bar"},
+				{name: "abar", path: "github.com/foo/bar"},
+				{path: "github.com/foo/bar/baz"},
+				{path: "github.com/foo/bar-baz"},
+			},
+		},
+		{
+			sortOrder: ImportSortOrderAlias,
+			expectedImportSortKey: []importSortKey{
+				{name: "abar", path: "github.com/foo/bar"},
+				{path: "github.com/foo/bar-baz"},
+				{path: "github.com/foo/bar/baz"},
+				{path: "github.com/Foo/qux"},
+				{name: "zbar", path: "github.com/foo/bar"},
+			},
+		},
+		{
+			sortOrder: ImportSortOrderAliasFirst,
+			expectedImportSortKey: []importSortKey{
+				{name: "zbar", path: "github.com/foo/bar"},
+				{name: "abar", path: "github.com/foo/bar"},
+				{path: "github.com/Foo/qux"},
+				{path: "github.com/foo/bar-baz"},
+				{path: "github.com/foo/bar/baz"},
+			},
+		},
+	} {
+		s.Require().Equal(testCase.expectedImportSortKey, s.sort(testCase.sortOrder, importSortKeys), testCase.sortOrder.String())
+	}
+}
+
+func (s *SortOrderTestSuite) TestVerify() {
+	contents := `package fixtures
+
+import (
+	"github.com/foo/bar"
+	"github.com/foo/bar/baz"
+	"github.com/foo/bar-baz"
+)
+`
+
+	s.options.SortOrder = ImportSortOrderParentFirst
+	s.Require().NoError(s.verify(contents))
+
+	s.options.SortOrder = ImportSortOrderLexical
+	err := s.verify(contents)
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "Import group 0 is not sorted")
+}
+
+func (s *SortOrderTestSuite) TestFix() {
+	fixer, err := newFixer()
+	s.Require().NoError(err)
+
+	fixedContents, err := fixer.fix("fixtures.go", []byte(`package fixtures
+
+import (
+	z "github.com/foo/bar"
+	"github.com/foo/baz"
+)
+`), &VerifyOptions{
+		Scheme:    ImportGroupVerificationSchemeStdLocalThirdParty,
+		SortOrder: ImportSortOrderAlias,
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(`package fixtures
+
+import (
+	"github.com/foo/baz"
+	z "github.com/foo/bar"
+)
+`, string(fixedContents))
+}
+
+func TestSortOrderTestSuite(t *testing.T) {
+	suite.Run(t, new(SortOrderTestSuite))
+}
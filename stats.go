@@ -0,0 +1,176 @@
+package impi
+
+import (
+	"time"
+)
+
+// reasons for which files are skipped, as counted in Stats
+const (
+	SkipReasonTest             = "test"
+	SkipReasonGenerated        = "generated"
+	SkipReasonSkipPath         = "skip-path"
+	SkipReasonIgnored          = "ignored"
+	SkipReasonBuildConstraints = "build-constraints"
+	SkipReasonUnparseable      = "unparseable"
+)
+
+// kinds of findings, as reported in VerificationError
+const (
+
+	// FindingKindViolation is a violation of a rule
+	FindingKindViolation = "violation"
+
+	// FindingKindParseError is a syntax error, which prevents the file from being verified unless it
+	// follows the imports
+	FindingKindParseError = "parse-error"
+
+	// FindingKindFailure is any other failure to verify a file, e.g. to read or fix it
+	FindingKindFailure = "failure"
+)
+
+// importTypeStatsName names the classes of imports counted in Stats
+var importTypeStatsName = map[importType]string{
+	importTypeStd:               "std",
+	importTypeLocal:             "local",
+	importTypeThirdParty:        "third-party",
+	importTypeLocalOrThirdParty: "local-or-third-party",
+}
+
+// findImportTypeByStatsName returns the class of imports named in Stats, or importTypeUnknown
+func findImportTypeByStatsName(statsName string) importType {
+	for classifiedType, classifiedTypeStatsName := range importTypeStatsName {
+		if classifiedTypeStatsName == statsName {
+			return classifiedType
+		}
+	}
+
+	return importTypeUnknown
+}
+
+// Stats summarizes a verification run
+type Stats struct {
+
+	// NumFilesScanned is the number of files verified, not including skipped files
+	NumFilesScanned int `json:"files-scanned"`
+
+	// NumFilesSkipped is the number of files skipped, by the reason they were skipped for
+	NumFilesSkipped map[string]int `json:"files-skipped"`
+
+	// NumFilesWithViolations is the number of files for which errors were reported
+	NumFilesWithViolations int `json:"files-with-violations"`
+
+	// NumFilesFixed is the number of files whose imports were rewritten
+	NumFilesFixed int `json:"files-fixed"`
+
+	// NumViolationsByRule is the number of violations reported, by the rule which raised them
+	NumViolationsByRule map[string]int `json:"violations-per-rule"`
+
+	// NumFailures is the number of errors which aren't violations of a rule - failures to read, parse or fix
+	// files
+	NumFailures int `json:"failures"`
+
+	// NumImportsByClass is the number of imports in the scanned files, by their class (std, local,
+	// third-party or local-or-third-party if there's no local prefix)
+	NumImportsByClass map[string]int `json:"imports-per-class"`
+
+	// Elapsed is how long the run took
+	Elapsed time.Duration `json:"elapsed-ns"`
+}
+
+// fileStats are the statistics of verifying a single file, along with the imports of the file if they are
+// to be verified against those of the other files of its package
+type fileStats struct {
+	skipReason        string
+	numImportsByClass map[string]int
+
+	// the imports verified across the files of the package, by the package name the file declares. Whether
+	// their findings are only reported depends on the file
+	imports     []fileImport
+	packageName string
+	reportOnly  bool
+}
+
+func newStats() Stats {
+	return Stats{
+		NumFilesSkipped:     map[string]int{},
+		NumViolationsByRule: map[string]int{},
+		NumImportsByClass:   map[string]int{},
+	}
+}
+
+// Add adds the statistics of another run, e.g. of another root path
+func (s *Stats) Add(other *Stats) {
+	s.NumFilesScanned += other.NumFilesScanned
+	s.NumFilesWithViolations += other.NumFilesWithViolations
+	s.NumFilesFixed += other.NumFilesFixed
+	s.NumFailures += other.NumFailures
+	s.Elapsed += other.Elapsed
+
+	s.NumFilesSkipped = addCounts(s.NumFilesSkipped, other.NumFilesSkipped)
+	s.NumViolationsByRule = addCounts(s.NumViolationsByRule, other.NumViolationsByRule)
+	s.NumImportsByClass = addCounts(s.NumImportsByClass, other.NumImportsByClass)
+}
+
+// GetStats returns the statistics of the last run of Verify or Migrate
+func (i *Impi) GetStats() Stats {
+	i.statsLock.Lock()
+	defer i.statsLock.Unlock()
+
+	return i.stats
+}
+
+// addFileStats counts a file as either scanned or skipped
+func (i *Impi) addFileStats(filePath string, fileStats *fileStats) {
+	i.statsLock.Lock()
+	defer i.statsLock.Unlock()
+
+	if fileStats.imports != nil {
+		i.packageFiles[filePath] = *fileStats
+	}
+
+	if fileStats.skipReason != "" {
+		i.stats.NumFilesSkipped[fileStats.skipReason]++
+		return
+	}
+
+	i.stats.NumFilesScanned++
+	i.stats.NumImportsByClass = addCounts(i.stats.NumImportsByClass, fileStats.numImportsByClass)
+}
+
+func (i *Impi) addFixedFile() {
+	i.statsLock.Lock()
+	defer i.statsLock.Unlock()
+
+	i.stats.NumFilesFixed++
+}
+
+// addVerificationErrorStats counts a reported error. Errors are reported from a single goroutine, so files
+// with violations can be counted without locking the set of files
+func (i *Impi) addVerificationErrorStats(verificationError *VerificationError, filesWithViolations map[string]bool) {
+	i.statsLock.Lock()
+	defer i.statsLock.Unlock()
+
+	// failures to verify files aren't violations of any rule
+	if verificationError.Kind == FindingKindViolation {
+		i.stats.NumViolationsByRule[verificationError.Rule]++
+	} else {
+		i.stats.NumFailures++
+	}
+
+	if !filesWithViolations[verificationError.FilePath] {
+		filesWithViolations[verificationError.FilePath] = true
+		i.stats.NumFilesWithViolations++
+	}
+}
+
+func addCounts(counts map[string]int, otherCounts map[string]int) map[string]int {
+	if counts == nil {
+		counts = map[string]int{}
+	}
+
+	for key, count := range otherCounts {
+		counts[key] += count
+	}
+
+	return counts
+}
@@ -0,0 +1,96 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type collectingErrorReporter struct {
+	verificationErrors []VerificationError
+}
+
+func (cer *collectingErrorReporter) Report(err VerificationError) {
+	cer.verificationErrors = append(cer.verificationErrors, err)
+}
+
+type StatsTestSuite struct {
+	suite.Suite
+	tempDir string
+}
+
+func (s *StatsTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-stats")
+	s.Require().NoError(err)
+
+	for filePath, contents := range map[string]string{
+		"a.go": `package fixtures
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pavius/impi/foo"
+
+	"github.com/other/bar"
+)
+`,
+		"b.go": `package fixtures
+
+import (
+	. "os"
+	"fmt"
+)
+`,
+		"a_test.go":    "package fixtures\n",
+		"generated.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage fixtures\n",
+		"skipped.go":   "package fixtures\n",
+	} {
+		s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, filePath), []byte(contents), 0644))
+	}
+}
+
+func (s *StatsTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *StatsTestSuite) TestStats() {
+	impi, err := NewImpi(2)
+	s.Require().NoError(err)
+
+	err = impi.Verify(s.tempDir, &VerifyOptions{
+		Scheme:           ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix:      "github.com/pavius/impi",
+		SkipTests:        true,
+		Generated:        GeneratedFilesPolicySkip,
+		SkipPaths:        []string{"skipped"},
+		ForbidDotImports: true,
+	}, &collectingErrorReporter{})
+	s.Require().Error(err)
+
+	stats := impi.GetStats()
+	s.Require().Equal(2, stats.NumFilesScanned)
+	s.Require().Equal(map[string]int{
+		SkipReasonTest:      1,
+		SkipReasonGenerated: 1,
+		SkipReasonSkipPath:  1,
+	}, stats.NumFilesSkipped)
+	s.Require().Equal(1, stats.NumFilesWithViolations)
+	s.Require().Equal(map[string]int{ruleDotImport: 1, ruleImportGroups: 1}, stats.NumViolationsByRule)
+	s.Require().Equal(map[string]int{"std": 4, "local": 1, "third-party": 1}, stats.NumImportsByClass)
+	s.Require().NotZero(stats.Elapsed)
+
+	// statistics of several runs add up
+	stats.Add(&stats)
+	s.Require().Equal(4, stats.NumFilesScanned)
+	s.Require().Equal(2, stats.NumFilesSkipped[SkipReasonTest])
+}
+
+func TestStatsTestSuite(t *testing.T) {
+	suite.Run(t, new(StatsTestSuite))
+}
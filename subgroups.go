@@ -0,0 +1,289 @@
+package impi
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// importSubgroupKey identifies the sub-group of an import within the group of its class. Sub-groups are
+// ordered by the index of their prefix and then by module path
+type importSubgroupKey struct {
+	prefixIndex int
+	modulePath  string
+}
+
+func (isk importSubgroupKey) less(other importSubgroupKey) bool {
+	if isk.prefixIndex != other.prefixIndex {
+		return isk.prefixIndex < other.prefixIndex
+	}
+
+	return isk.modulePath < other.modulePath
+}
+
+// importSubgrouper assigns the imports of a file to the sub-groups of their class
+type importSubgrouper struct {
+	importSubgroupsByType map[importType]*ImportSubgroups
+	requiredModulePaths   []string
+}
+
+// hosts whose repositories reside under a user or an organization, and are therefore identified by
+// three path elements rather than two
+var threeElementRepositoryHosts = []string{
+	"github.com",
+	"gitlab.com",
+	"bitbucket.org",
+	"golang.org",
+}
+
+func newImportSubgrouper(filePath string, verifyOptions *VerifyOptions) (*importSubgrouper, error) {
+	importSubgroupsByType, err := getImportSubgroupsByType(verifyOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	subgrouper := &importSubgrouper{
+		importSubgroupsByType: importSubgroupsByType,
+	}
+
+	// modules are only looked up if sub-groups are split by them
+	for _, importSubgroups := range importSubgroupsByType {
+		if !importSubgroups.ByModule {
+			continue
+		}
+
+		module, err := findGoModule(filepath.Dir(filePath))
+		if err != nil {
+			return nil, err
+		}
+
+		if module != nil {
+			subgrouper.requiredModulePaths = append([]string{module.path}, module.requires...)
+		}
+
+		break
+	}
+
+	return subgrouper, nil
+}
+
+// getImportSubgroupsByType returns the sub-groups of the options by the type of imports they split
+func getImportSubgroupsByType(verifyOptions *VerifyOptions) (map[importType]*ImportSubgroups, error) {
+	importSubgroupsByType := map[importType]*ImportSubgroups{}
+
+	for importSubgroupsIndex := range verifyOptions.Subgroups {
+		importSubgroups := &verifyOptions.Subgroups[importSubgroupsIndex]
+
+		classifiedType := findImportTypeByStatsName(importSubgroups.Class)
+		if classifiedType == importTypeUnknown {
+			return nil, fmt.Errorf("Unknown class of imports in sub-groups: %q", importSubgroups.Class)
+		}
+
+		if _, found := importSubgroupsByType[classifiedType]; found {
+			return nil, fmt.Errorf("Sub-groups of %s imports are specified more than once", importSubgroups.Class)
+		}
+
+		importSubgroupsByType[classifiedType] = importSubgroups
+	}
+
+	return importSubgroupsByType, nil
+}
+
+// getSubgroupKey returns the sub-group of an import, or false if its class isn't split into sub-groups
+func (is *importSubgrouper) getSubgroupKey(importPath string, classifiedType importType) (importSubgroupKey, bool) {
+	importSubgroups, found := is.importSubgroupsByType[classifiedType]
+	if !found {
+		return importSubgroupKey{}, false
+	}
+
+	subgroupKey := importSubgroupKey{prefixIndex: len(importSubgroups.Prefixes)}
+	matchedPrefixLength := -1
+
+	for prefixIndex, prefix := range importSubgroups.Prefixes {
+		if len(prefix) > matchedPrefixLength && matchImportPathPrefixes([]string{prefix}, importPath) {
+			subgroupKey.prefixIndex = prefixIndex
+			matchedPrefixLength = len(prefix)
+		}
+	}
+
+	if matchedPrefixLength == -1 && importSubgroups.ByModule {
+		subgroupKey.modulePath = is.getModulePath(importPath)
+	}
+
+	return subgroupKey, true
+}
+
+// getSubgroupedType returns the type of the imports of a group if they're all of a single type which
+// is split into sub-groups
+func (is *importSubgrouper) getSubgroupedType(importInfoGroup *importInfoGroup) (importType, bool) {
+	if len(importInfoGroup.importInfos) == 0 {
+		return importTypeUnknown, false
+	}
+
+	groupImportType := importInfoGroup.importInfos[0].classifiedType
+
+	for _, importInfo := range importInfoGroup.importInfos {
+		if importInfo.classifiedType != groupImportType {
+			return importTypeUnknown, false
+		}
+	}
+
+	_, found := is.importSubgroupsByType[groupImportType]
+
+	return groupImportType, found
+}
+
+// getModulePath returns the path of the module an import path belongs to
+func (is *importSubgrouper) getModulePath(importPath string) string {
+	modulePath := ""
+
+	for _, requiredModulePath := range is.requiredModulePaths {
+		if len(requiredModulePath) > len(modulePath) && matchImportPathPrefixes([]string{requiredModulePath}, importPath) {
+			modulePath = requiredModulePath
+		}
+	}
+
+	if modulePath != "" {
+		return modulePath
+	}
+
+	// without a go.mod that requires it, the module is assumed to be the repository
+	pathElements := strings.Split(importPath, "/")
+	numRepositoryPathElements := 2
+
+	for _, threeElementRepositoryHost := range threeElementRepositoryHosts {
+		if pathElements[0] == threeElementRepositoryHost {
+			numRepositoryPathElements = 3
+		}
+	}
+
+	if len(pathElements) > numRepositoryPathElements {
+		pathElements = pathElements[:numRepositoryPathElements]
+	}
+
+	return strings.Join(pathElements, "/")
+}
+
+// mergeImportSubgroups merges consecutive groups holding sub-groups of the same class, so that the
+// scheme sees a single group per class
+func (v *verifier) mergeImportSubgroups(importInfoGroups []importInfoGroup,
+	importSubgrouper *importSubgrouper) []importInfoGroup {
+	var mergedImportInfoGroups []importInfoGroup
+	previousSubgroupedType := importTypeUnknown
+
+	for importInfoGroupIndex := range importInfoGroups {
+		currentImportInfoGroup := &importInfoGroups[importInfoGroupIndex]
+
+		subgroupedType, subgrouped := importSubgrouper.getSubgroupedType(currentImportInfoGroup)
+		if subgrouped && subgroupedType == previousSubgroupedType {
+			lastMergedImportInfoGroup := &mergedImportInfoGroups[len(mergedImportInfoGroups)-1]
+			lastMergedImportInfoGroup.importInfos = append(lastMergedImportInfoGroup.importInfos,
+				currentImportInfoGroup.importInfos...)
+
+			continue
+		}
+
+		// copy the imports so that merging doesn't modify the group
+		mergedImportInfoGroups = append(mergedImportInfoGroups, importInfoGroup{
+			importInfos: append([]*importInfo{}, currentImportInfoGroup.importInfos...),
+		})
+
+		previousSubgroupedType = importTypeUnknown
+		if subgrouped {
+			previousSubgroupedType = subgroupedType
+		}
+	}
+
+	return mergedImportInfoGroups
+}
+
+// verifyImportSubgroups verifies that each group of a class split into sub-groups holds a single sub-group,
+// and that the sub-groups of the class follow each other in order
+func (v *verifier) verifyImportSubgroups(importInfoGroups []importInfoGroup, importSubgrouper *importSubgrouper) error {
+	previousSubgroupedType := importTypeUnknown
+	var previousSubgroupKey importSubgroupKey
+	var previousImportInfo *importInfo
+
+	for importInfoGroupIndex := range importInfoGroups {
+		importInfoGroup := &importInfoGroups[importInfoGroupIndex]
+
+		subgroupedType, subgrouped := importSubgrouper.getSubgroupedType(importInfoGroup)
+		if !subgrouped {
+			previousSubgroupedType = importTypeUnknown
+			continue
+		}
+
+		firstImportInfo := importInfoGroup.importInfos[0]
+		subgroupKey, _ := importSubgrouper.getSubgroupKey(firstImportInfo.path, subgroupedType)
+
+		for _, importInfo := range importInfoGroup.importInfos[1:] {
+			if otherSubgroupKey, _ := importSubgrouper.getSubgroupKey(importInfo.path, subgroupedType); otherSubgroupKey != subgroupKey {
+				return fmt.Errorf("Imports of different sub-groups are not allowed in the same group (%d): %s != %s",
+					importInfoGroupIndex,
+					firstImportInfo.path,
+					importInfo.path)
+			}
+		}
+
+		if subgroupedType == previousSubgroupedType && !previousSubgroupKey.less(subgroupKey) {
+			if previousSubgroupKey == subgroupKey {
+				return fmt.Errorf("Imports of the same sub-group must reside in a single group (%d): %s, %s",
+					importInfoGroupIndex,
+					previousImportInfo.path,
+					firstImportInfo.path)
+			}
+
+			return fmt.Errorf("Import sub-groups are not in the proper order (%d): %s should precede %s",
+				importInfoGroupIndex,
+				firstImportInfo.path,
+				previousImportInfo.path)
+		}
+
+		previousSubgroupedType = subgroupedType
+		previousSubgroupKey = subgroupKey
+		previousImportInfo = firstImportInfo
+	}
+
+	return nil
+}
+
+// splitFixedImportGroup splits a sorted group of imports into its sub-groups, in order
+func (is *importSubgrouper) splitFixedImportGroup(fixedImportGroup []*fixedImport) [][]*fixedImport {
+	groupImportType := fixedImportGroup[0].classifiedType
+
+	for _, groupFixedImport := range fixedImportGroup {
+		if groupFixedImport.classifiedType != groupImportType {
+			return [][]*fixedImport{fixedImportGroup}
+		}
+	}
+
+	if _, found := is.importSubgroupsByType[groupImportType]; !found {
+		return [][]*fixedImport{fixedImportGroup}
+	}
+
+	var subgroupKeys []importSubgroupKey
+	fixedImportsBySubgroupKey := map[importSubgroupKey][]*fixedImport{}
+
+	for _, groupFixedImport := range fixedImportGroup {
+		subgroupKey, _ := is.getSubgroupKey(groupFixedImport.path, groupImportType)
+
+		if _, found := fixedImportsBySubgroupKey[subgroupKey]; !found {
+			subgroupKeys = append(subgroupKeys, subgroupKey)
+		}
+
+		fixedImportsBySubgroupKey[subgroupKey] = append(fixedImportsBySubgroupKey[subgroupKey], groupFixedImport)
+	}
+
+	sort.Slice(subgroupKeys, func(first, second int) bool {
+		return subgroupKeys[first].less(subgroupKeys[second])
+	})
+
+	var fixedImportSubgroups [][]*fixedImport
+
+	for _, subgroupKey := range subgroupKeys {
+		fixedImportSubgroups = append(fixedImportSubgroups, fixedImportsBySubgroupKey[subgroupKey])
+	}
+
+	return fixedImportSubgroups
+}
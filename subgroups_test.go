@@ -0,0 +1,205 @@
+package impi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SubgroupsTestSuite struct {
+	VerifierTestSuite
+}
+
+func (s *SubgroupsTestSuite) SetupSuite() {
+	s.options.Scheme = ImportGroupVerificationSchemeStdLocalThirdParty
+	s.options.LocalPrefix = "github.com/pavius/impi"
+	s.options.Subgroups = []ImportSubgroups{
+		{
+			Class:    "third-party",
+			Prefixes: []string{"golang.org/x", "github.com"},
+		},
+	}
+}
+
+func (s *SubgroupsTestSuite) TestVerify() {
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Sub-groups in order",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/pavius/impi/foo"
+
+	"golang.org/x/net"
+	"golang.org/x/sync"
+
+	"github.com/foo/bar"
+
+	"k8s.io/api"
+	"k8s.io/client-go"
+)
+`,
+		},
+		{
+			name: "Some sub-groups",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"golang.org/x/net"
+
+	"k8s.io/api"
+)
+`,
+		},
+		{
+			name: "Sub-groups in one group",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"golang.org/x/net"
+	"k8s.io/api"
+)
+`,
+			expectedErrorStrings: []string{
+				"Imports of different sub-groups are not allowed in the same group (1): golang.org/x/net != k8s.io/api",
+			},
+		},
+		{
+			name: "Sub-groups out of order",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"github.com/foo/bar"
+
+	"golang.org/x/net"
+)
+`,
+			expectedErrorStrings: []string{
+				"Import sub-groups are not in the proper order (2): golang.org/x/net should precede github.com/foo/bar",
+			},
+		},
+		{
+			name: "Sub-group split",
+			contents: `package fixtures
+
+import (
+	"fmt"
+
+	"golang.org/x/net"
+
+	"golang.org/x/sync"
+)
+`,
+			expectedErrorStrings: []string{
+				"Imports of the same sub-group must reside in a single group (2): golang.org/x/net, golang.org/x/sync",
+			},
+		},
+		{
+			name: "Class out of order",
+			contents: `package fixtures
+
+import (
+	"golang.org/x/net"
+
+	"github.com/foo/bar"
+
+	"fmt"
+)
+`,
+			expectedErrorStrings: []string{
+				`Import groups are not in the proper order: ["Third party" "Std"]`,
+			},
+		},
+	}
+
+	s.verifyTestCases(verificationTestCases)
+}
+
+func (s *SubgroupsTestSuite) TestGetSubgroupKeyByModule() {
+	importSubgrouper := &importSubgrouper{
+		importSubgroupsByType: map[importType]*ImportSubgroups{
+			importTypeThirdParty: {
+				Class:    "third-party",
+				Prefixes: []string{"golang.org/x"},
+				ByModule: true,
+			},
+		},
+		requiredModulePaths: []string{"github.com/aws/aws-sdk-go-v2", "github.com/aws/aws-sdk-go-v2/service/s3"},
+	}
+
+	for _, testCase := range []struct {
+		importPath          string
+		expectedSubgroupKey importSubgroupKey
+	}{
+		{"golang.org/x/net/context", importSubgroupKey{prefixIndex: 0}},
+		{"github.com/aws/aws-sdk-go-v2/aws", importSubgroupKey{prefixIndex: 1, modulePath: "github.com/aws/aws-sdk-go-v2"}},
+		{"github.com/aws/aws-sdk-go-v2/service/s3/types", importSubgroupKey{prefixIndex: 1, modulePath: "github.com/aws/aws-sdk-go-v2/service/s3"}},
+		{"github.com/foo/bar/baz", importSubgroupKey{prefixIndex: 1, modulePath: "github.com/foo/bar"}},
+		{"k8s.io/api/core/v1", importSubgroupKey{prefixIndex: 1, modulePath: "k8s.io/api"}},
+		{"gopkg.in/yaml.v3", importSubgroupKey{prefixIndex: 1, modulePath: "gopkg.in/yaml.v3"}},
+	} {
+		subgroupKey, found := importSubgrouper.getSubgroupKey(testCase.importPath, importTypeThirdParty)
+		s.Require().True(found)
+		s.Require().Equal(testCase.expectedSubgroupKey, subgroupKey, testCase.importPath)
+	}
+
+	_, found := importSubgrouper.getSubgroupKey("fmt", importTypeStd)
+	s.Require().False(found)
+}
+
+func (s *SubgroupsTestSuite) TestFix() {
+	fixer, err := newFixer()
+	s.Require().NoError(err)
+
+	fixedContents, err := fixer.fix("fixtures.go", []byte(`package fixtures
+
+import (
+	"fmt"
+	"github.com/foo/bar"
+	"k8s.io/client-go"
+	"golang.org/x/sync"
+	"github.com/pavius/impi/foo"
+	"k8s.io/api"
+	"golang.org/x/net"
+)
+`), &s.options)
+	s.Require().NoError(err)
+
+	s.Require().Equal(`package fixtures
+
+import (
+	"fmt"
+
+	"github.com/pavius/impi/foo"
+
+	"golang.org/x/net"
+	"golang.org/x/sync"
+
+	"github.com/foo/bar"
+
+	"k8s.io/api"
+	"k8s.io/client-go"
+)
+`, string(fixedContents))
+
+	s.Require().NoError(s.verify(string(fixedContents)))
+}
+
+func (s *SubgroupsTestSuite) TestUnknownClass() {
+	_, err := getImportSubgroupsByType(&VerifyOptions{
+		Subgroups: []ImportSubgroups{{Class: "vendored"}},
+	})
+	s.Require().EqualError(err, `Unknown class of imports in sub-groups: "vendored"`)
+}
+
+func TestSubgroupsTestSuite(t *testing.T) {
+	suite.Run(t, new(SubgroupsTestSuite))
+}
@@ -0,0 +1,94 @@
+package impi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// verifyPackageUnderTest marks the import of the package under test, if the file is of an external test
+// package, and verifies that it's placed where the options specify
+func (v *verifier) verifyPackageUnderTest(importInfoGroups []importInfoGroup) (ruleViolations, error) {
+	if v.verifyOptions.PackageUnderTest == PackageUnderTestPlacementLocal {
+		return nil, nil
+	}
+
+	packageUnderTestPath, err := getPackageUnderTestPath(v.filePath, v.packageName)
+	if err != nil || packageUnderTestPath == "" {
+		return nil, err
+	}
+
+	var violations ruleViolations
+
+	for importInfoGroupIndex, importInfoGroup := range importInfoGroups {
+		for importInfoIndex, importInfo := range importInfoGroup.importInfos {
+			if importInfo.path != packageUnderTestPath {
+				continue
+			}
+
+			importInfo.isPackageUnderTest = true
+
+			var message string
+
+			switch v.verifyOptions.PackageUnderTest {
+			case PackageUnderTestPlacementFirstLocal:
+				if importInfoIndex != 0 {
+					message = "The package under test must be the first import of its group"
+				}
+			case PackageUnderTestPlacementLastGroup:
+				if importInfoGroupIndex != len(importInfoGroups)-1 || len(importInfoGroup.importInfos) != 1 {
+					message = "The package under test must reside in a trailing group of its own"
+				}
+			}
+
+			if message != "" {
+				violations = append(violations, &ruleViolation{
+					rule:    rulePackageUnderTest,
+					lineNum: importInfo.lineNum,
+					message: fmt.Sprintf("%s: %s", message, strings.TrimSpace(importInfo.lineValue)),
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// filterPackageUnderTestGroup removes the trailing group if it only holds the package under test
+func (v *verifier) filterPackageUnderTestGroup(importInfoGroups []importInfoGroup) []importInfoGroup {
+	if len(importInfoGroups) == 0 {
+		return importInfoGroups
+	}
+
+	lastImportInfoGroup := importInfoGroups[len(importInfoGroups)-1]
+	if len(lastImportInfoGroup.importInfos) != 1 || !lastImportInfoGroup.importInfos[0].isPackageUnderTest {
+		return importInfoGroups
+	}
+
+	return importInfoGroups[:len(importInfoGroups)-1]
+}
+
+// getPackageUnderTestPath returns the import path of the package an external test package (e.g. foo_test)
+// tests - the package in the same directory. An empty string is returned for other files, or if the import
+// path of the directory isn't known
+func getPackageUnderTestPath(filePath string, packageName string) (string, error) {
+	if !isTestFilePath(filePath) || !strings.HasSuffix(packageName, "_test") {
+		return "", nil
+	}
+
+	dirPath, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return "", err
+	}
+
+	module, err := findGoModule(dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	if module != nil {
+		return module.getPackagePath(dirPath)
+	}
+
+	return getGoPathImportPath(dirPath)
+}
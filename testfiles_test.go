@@ -0,0 +1,191 @@
+package impi
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestFilesTestSuite struct {
+	VerifierTestSuite
+	tempDir string
+}
+
+func (s *TestFilesTestSuite) SetupTest() {
+	var err error
+
+	s.tempDir, err = ioutil.TempDir("", "impi-testfiles")
+	s.Require().NoError(err)
+
+	s.Require().NoError(ioutil.WriteFile(path.Join(s.tempDir, "go.mod"), []byte("module github.com/pavius/impi\n"), 0644))
+	s.Require().NoError(os.MkdirAll(path.Join(s.tempDir, "foo"), 0755))
+
+	s.filePath = path.Join(s.tempDir, "foo", "foo_test.go")
+	s.VerifierTestSuite.SetupTest()
+
+	s.options = VerifyOptions{
+		Scheme:      ImportGroupVerificationSchemeStdLocalThirdParty,
+		LocalPrefix: "github.com/pavius/impi",
+	}
+}
+
+func (s *TestFilesTestSuite) TearDownTest() {
+	os.RemoveAll(s.tempDir)
+}
+
+func (s *TestFilesTestSuite) TestTestScheme() {
+	contents := `package foo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/pavius/impi/bar"
+)
+`
+
+	s.Require().Error(s.verify(contents))
+
+	testScheme := ImportGroupVerificationSchemeStdThirdPartyLocal
+	s.options.TestScheme = &testScheme
+	s.Require().NoError(s.verify(contents))
+
+	// production files are still verified against the scheme
+	s.filePath = path.Join(s.tempDir, "foo", "foo.go")
+	s.Require().Error(s.verify(contents))
+}
+
+func (s *TestFilesTestSuite) TestReportOnly() {
+	s.options.Tests = TestFilesPolicyReportOnly
+
+	err := s.verify(`package foo_test
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+`)
+	s.Require().Error(err)
+	s.Require().True(err.(ruleViolations)[0].reportOnly)
+}
+
+func (s *TestFilesTestSuite) TestPackageUnderTestLastGroup() {
+	s.options.PackageUnderTest = PackageUnderTestPlacementLastGroup
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "Trailing group",
+			contents: `package foo_test
+
+import (
+	"testing"
+
+	"github.com/pavius/impi/bar"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/pavius/impi/foo"
+)
+`,
+		},
+		{
+			name: "Among local imports",
+			contents: `package foo_test
+
+import (
+	"testing"
+
+	"github.com/pavius/impi/bar"
+	"github.com/pavius/impi/foo"
+
+	"github.com/stretchr/testify/suite"
+)
+`,
+			expectedErrorStrings: []string{
+				`The package under test must reside in a trailing group of its own: "github.com/pavius/impi/foo"`,
+			},
+		},
+	}
+
+	s.verifyTestCases(verificationTestCases)
+
+	fixer, err := newFixer()
+	s.Require().NoError(err)
+
+	fixedContents, err := fixer.fix(s.filePath, []byte(verificationTestCases[1].contents), &s.options)
+	s.Require().NoError(err)
+	s.Require().Equal(verificationTestCases[0].contents, string(fixedContents))
+}
+
+func (s *TestFilesTestSuite) TestPackageUnderTestFirstLocal() {
+	s.options.PackageUnderTest = PackageUnderTestPlacementFirstLocal
+
+	verificationTestCases := []verificationTestCase{
+		{
+			name: "First",
+			contents: `package foo_test
+
+import (
+	"testing"
+
+	"github.com/pavius/impi/foo"
+	"github.com/pavius/impi/bar"
+	"github.com/pavius/impi/baz"
+)
+`,
+		},
+		{
+			name: "Sorted",
+			contents: `package foo_test
+
+import (
+	"testing"
+
+	"github.com/pavius/impi/bar"
+	"github.com/pavius/impi/baz"
+	"github.com/pavius/impi/foo"
+)
+`,
+			expectedErrorStrings: []string{
+				`The package under test must be the first import of its group: "github.com/pavius/impi/foo"`,
+			},
+		},
+	}
+
+	s.verifyTestCases(verificationTestCases)
+
+	fixer, err := newFixer()
+	s.Require().NoError(err)
+
+	fixedContents, err := fixer.fix(s.filePath, []byte(verificationTestCases[1].contents), &s.options)
+	s.Require().NoError(err)
+	s.Require().Equal(verificationTestCases[0].contents, string(fixedContents))
+
+	// internal test packages can't import the package they test
+	s.Require().NoError(s.verify(`package foo
+
+import (
+	"github.com/pavius/impi/bar"
+	"github.com/pavius/impi/foo"
+)
+`))
+}
+
+func (s *TestFilesTestSuite) TestSkip() {
+	s.Require().NoError(ioutil.WriteFile(s.filePath, []byte("package foo_test\n"), 0644))
+
+	impi, err := NewImpi(1)
+	s.Require().NoError(err)
+
+	s.options.Tests = TestFilesPolicySkip
+	s.Require().NoError(impi.Verify(s.filePath, &s.options, &collectingErrorReporter{}))
+	s.Require().Equal(1, impi.GetStats().NumFilesSkipped[SkipReasonTest])
+}
+
+func TestTestFilesTestSuite(t *testing.T) {
+	suite.Run(t, new(TestFilesTestSuite))
+}
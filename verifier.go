@@ -4,26 +4,37 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"go/ast"
 	"go/parser"
 	"go/scanner"
 	"go/token"
 	"io"
-	"io/ioutil"
 	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 )
 
-// This regex is to appear in generated code.
-var generatedRegex = regexp.MustCompile("// Code generated .* DO NOT EDIT\\.")
-
 type verifier struct {
-	verifyOptions *VerifyOptions
+	verifyOptions          *VerifyOptions
+	filePath               string
+	importSpecsByLine      map[int]*ast.ImportSpec
+	declDocImportSpecs     map[*ast.ImportSpec]bool
+	commentLineNums        map[int]bool
+	cgoImportViolations    ruleViolations
+	parseViolation         *ruleViolation
+	packageName            string
+	allowlistFilePrefixes  map[string][]string
+	packageNames           map[string]string
+	generatedMarkerRegexes map[string]*regexp.Regexp
+	fileStats              fileStats
 }
 
 type importInfoGroup struct {
 	importInfos []*importInfo
+
+	// hasHeaderComment is set if comment lines precede the first import of the group
+	hasHeaderComment bool
 }
 
 type importType int
@@ -57,45 +68,146 @@ type verificationScheme interface {
 }
 
 type importInfo struct {
-	lineNum        int
-	lineValue      string
-	path           string
-	classifiedType importType
+	lineNum            int
+	lineValue          string
+	path               string
+	name               string
+	hasComment         bool
+	hasTrailingComment bool
+	isPackageUnderTest bool
+	classifiedType     importType
+}
+
+const (
+	ruleImportGroups       = "import-groups"
+	ruleDotImport          = "dot-import"
+	ruleBlankImportComment = "blank-import-comment"
+	ruleBlankImportGroup   = "blank-import-group"
+	ruleRedundantAlias     = "redundant-alias"
+	ruleAliasFormat        = "alias-format"
+	ruleAliasRequired      = "alias-required"
+	ruleCanonicalAlias     = "canonical-alias"
+	ruleBannedImport       = "banned-import"
+	ruleImportBoundary     = "import-boundary"
+	ruleInternalImport     = "internal-import"
+	ruleThirdPartyImport   = "third-party-allowlist"
+	ruleMigration          = "migration"
+	ruleGroupHeader        = "group-header"
+	ruleTrailingComment    = "trailing-comment"
+	ruleDuplicateImport    = "duplicate-import"
+	ruleInconsistentAlias  = "inconsistent-alias"
+	ruleCgoImport          = "cgo-import"
+	rulePackageUnderTest   = "package-under-test"
+	ruleParseError         = "parse-error"
+)
+
+// ruleNames are the names of all the rules, to which severities may be assigned
+var ruleNames = []string{
+	ruleImportGroups,
+	ruleDotImport,
+	ruleBlankImportComment,
+	ruleBlankImportGroup,
+	ruleRedundantAlias,
+	ruleAliasFormat,
+	ruleAliasRequired,
+	ruleCanonicalAlias,
+	ruleBannedImport,
+	ruleImportBoundary,
+	ruleInternalImport,
+	ruleThirdPartyImport,
+	ruleMigration,
+	ruleGroupHeader,
+	ruleTrailingComment,
+	ruleDuplicateImport,
+	ruleInconsistentAlias,
+	ruleCgoImport,
+	rulePackageUnderTest,
+	ruleParseError,
+}
+
+// ruleViolation is an error raised by a named rule, at a specific line if lineNum is set. Violations
+// which are only reported don't fail verification
+type ruleViolation struct {
+	rule       string
+	lineNum    int
+	column     int
+	message    string
+	reportOnly bool
+	severity   Severity
+}
+
+func (rv *ruleViolation) Error() string {
+	return rv.message
+}
+
+// ruleViolations holds all the violations found in a single file
+type ruleViolations []*ruleViolation
+
+func (rvs ruleViolations) Error() string {
+	var messages []string
+
+	for _, violation := range rvs {
+		messages = append(messages, violation.Error())
+	}
+
+	return strings.Join(messages, "\n")
 }
 
 func newVerifier() (*verifier, error) {
-	return &verifier{}, nil
+	return &verifier{
+		allowlistFilePrefixes:  map[string][]string{},
+		packageNames:           map[string]string{},
+		generatedMarkerRegexes: map[string]*regexp.Regexp{},
+	}, nil
 }
 
-func (v *verifier) verify(sourceFileReader io.ReadSeeker, verifyOptions *VerifyOptions) error {
+func (v *verifier) verify(filePath string, sourceFileReader io.ReadSeeker, verifyOptions *VerifyOptions) error {
 	v.verifyOptions = verifyOptions
+	v.filePath = filePath
+	v.fileStats = fileStats{}
+
+	// generated files are either skipped, checked or reported without failing verification
+	generatedFilesPolicy := verifyOptions.getGeneratedFilesPolicy()
+	reportOnly := false
 
-	if verifyOptions.IgnoreGenerated {
-		// The line specifying that the code was generated can be found anywhere
-		// within a file. In practice, it is the first line.
-		fileContents, err := ioutil.ReadAll(sourceFileReader)
+	if generatedFilesPolicy != GeneratedFilesPolicyCheck {
+		generated, err := v.isGenerated(sourceFileReader)
 		if err != nil {
 			return err
 		}
 
-		if generatedRegex.Match(fileContents) {
+		if generated && generatedFilesPolicy == GeneratedFilesPolicySkip {
+			v.fileStats.skipReason = SkipReasonGenerated
 			return nil
 		}
 
-		if _, err := sourceFileReader.Seek(0, 0); err != nil {
-			return err
-		}
+		reportOnly = generated
 	}
 
-	// get lines on which imports start and end
+	// violations in test files may only be reported
+	if isTestFilePath(filePath) && verifyOptions.getTestFilesPolicy() == TestFilesPolicyReportOnly {
+		reportOnly = true
+	}
+
+	// get lines on which imports start and end. files which fail to parse after their imports are verified
+	// nonetheless
 	importLineNumbers, err := v.getImportPos(sourceFileReader)
+	if v.parseViolation != nil && verifyOptions.SkipUnparseable {
+		v.fileStats.skipReason = SkipReasonUnparseable
+		return nil
+	}
+
 	if err != nil {
+		if v.parseViolation != nil {
+			return v.getVerificationError(nil, reportOnly)
+		}
+
 		return err
 	}
 
 	// if there's nothing, do nothing
 	if len(importLineNumbers) == 0 {
-		return nil
+		return v.getVerificationError(nil, reportOnly)
 	}
 
 	// get import lines - the value of the source file from the first import to the last
@@ -113,35 +225,155 @@ func (v *verifier) verify(sourceFileReader io.ReadSeeker, verifyOptions *VerifyO
 	// classify import info types - for each info type assign an "importType"
 	v.classifyImportTypes(importInfoGroups)
 
+	// count imports by class for the statistics of the run
+	v.fileStats.numImportsByClass = map[string]int{}
+
+	for _, importInfoGroup := range importInfoGroups {
+		for _, importInfo := range importInfoGroup.importInfos {
+			v.fileStats.numImportsByClass[importTypeStatsName[importInfo.classifiedType]]++
+		}
+	}
+
 	// get scheme by type
-	verificationScheme, err := v.getVerificationScheme()
+	verificationScheme, err := getVerificationScheme(verifyOptions.getScheme(filePath))
+	if err != nil {
+		return err
+	}
+
+	// verify that import "C" is declared the way cgo expects it
+	violations := append(ruleViolations{}, v.cgoImportViolations...)
+
+	// verify how imports are named (dot, blank and aliased imports)
+	violations = append(violations, v.verifyImportNames(importInfoGroups)...)
+
+	// verify that no path is imported more than once
+	if verifyOptions.ForbidDuplicateImports {
+		violations = append(violations, v.verifyDuplicateImports(importInfoGroups)...)
+	}
+
+	// the names of the imports are verified against those of the other files of the package once all
+	// the files are verified
+	if verifyOptions.RequireConsistentAliases {
+		v.fileStats.imports = getFileImports(importInfoGroups)
+		v.fileStats.packageName = v.packageName
+		v.fileStats.reportOnly = reportOnly
+	}
+
+	// verify that no banned imports are used
+	violations = append(violations, v.verifyBannedImports(importInfoGroups)...)
+
+	// verify the comments of imports and groups
+	commentViolations, err := v.verifyImportComments(importInfoGroups)
+	if err != nil {
+		return err
+	}
+
+	violations = append(violations, commentViolations...)
+
+	// verify that the package only imports the packages it may
+	boundaryViolations, err := v.verifyImportBoundaries(importInfoGroups)
+	if err != nil {
+		return err
+	}
+
+	violations = append(violations, boundaryViolations...)
+
+	// verify that the third party imports are allowed
+	allowlistViolations, err := v.verifyThirdPartyAllowlist(importInfoGroups)
+	if err != nil {
+		return err
+	}
+
+	violations = append(violations, allowlistViolations...)
+
+	// a trailing group of blank imports is not subject to the scheme
+	if verifyOptions.BlankImportsLast {
+		importInfoGroups = v.filterBlankImportGroup(importInfoGroups)
+	}
+
+	// verify where external test packages import the package under test
+	packageUnderTestViolations, err := v.verifyPackageUnderTest(importInfoGroups)
+	if err != nil {
+		return err
+	}
+
+	violations = append(violations, packageUnderTestViolations...)
+
+	// nor is the trailing group of the package under test, if it resides in one
+	if verifyOptions.PackageUnderTest == PackageUnderTestPlacementLastGroup {
+		importInfoGroups = v.filterPackageUnderTestGroup(importInfoGroups)
+	}
+
+	importSubgrouper, err := newImportSubgrouper(filePath, verifyOptions)
 	if err != nil {
 		return err
 	}
 
+	// verify the groups against the scheme
+	if err := v.verifyImportInfoGroups(importInfoGroups, verificationScheme, importSubgrouper); err != nil {
+		violations = append(violations, &ruleViolation{
+			rule:    ruleImportGroups,
+			message: err.Error(),
+		})
+	}
+
+	return v.getVerificationError(violations, reportOnly)
+}
+
+// getVerificationError returns the violations of the file, preceded by its syntax error if it only partially
+// parsed, or nil if there are none
+func (v *verifier) getVerificationError(violations ruleViolations, reportOnly bool) error {
+	if v.parseViolation != nil {
+		violations = append(ruleViolations{v.parseViolation}, violations...)
+	}
+
+	// findings of rules which are turned off aren't reported
+	violations = applySeverities(violations, v.verifyOptions)
+
+	if len(violations) != 0 {
+		for _, violation := range violations {
+			violation.reportOnly = reportOnly
+		}
+
+		return violations
+	}
+
+	return nil
+}
+
+func (v *verifier) verifyImportInfoGroups(importInfoGroups []importInfoGroup,
+	verificationScheme verificationScheme,
+	importSubgrouper *importSubgrouper) error {
+
+	// the scheme sees the sub-groups of a class as a single group
+	classImportInfoGroups := v.mergeImportSubgroups(importInfoGroups, importSubgrouper)
+
 	// verify that we don't have too many groups
-	if verificationScheme.getMaxNumGroups() < len(importInfoGroups) {
-		return fmt.Errorf("Expected no more than 3 groups, got %d", len(importInfoGroups))
+	if verificationScheme.getMaxNumGroups() < len(classImportInfoGroups) {
+		return fmt.Errorf("Expected no more than %d groups, got %d",
+			verificationScheme.getMaxNumGroups(),
+			len(classImportInfoGroups))
 	}
 
 	// if the scheme disallowed mixed groups, check that there are no mixed groups
 	if !verificationScheme.getMixedGroupsAllowed() {
-		if err := v.verifyNonMixedGroups(importInfoGroups); err != nil {
+		if err := v.verifyNonMixedGroups(classImportInfoGroups); err != nil {
 			return err
 		}
 
 		// verify group order
-		if err := v.verifyGroupOrder(importInfoGroups, verificationScheme.getAllowedImportOrders()); err != nil {
+		if err := v.verifyGroupOrder(classImportInfoGroups, verificationScheme.getAllowedImportOrders()); err != nil {
 			return err
 		}
 	}
 
-	// verify that all groups are sorted amongst themselves
-	if err := v.verifyImportInfoGroupsOrder(importInfoGroups); err != nil {
+	// verify that the sub-groups of each class are split and ordered
+	if err := v.verifyImportSubgroups(importInfoGroups, importSubgrouper); err != nil {
 		return err
 	}
 
-	return nil
+	// verify that all groups are sorted amongst themselves
+	return v.verifyImportInfoGroupsOrder(importInfoGroups)
 }
 
 func (v *verifier) groupImportInfos(importInfos []importInfo, importLineNumbers []int) []importInfoGroup {
@@ -157,10 +389,13 @@ func (v *verifier) groupImportInfos(importInfos []importInfo, importLineNumbers
 	// split the imports into groups, where groups are separated with empty lines
 	for _, importInfoInstance := range importInfos {
 
-		// if we found an empty line - open a new group
+		// if we found an empty line - open a new group, unless the current group holds no imports yet (e.g.
+		// it only holds a header comment)
 		if len(importInfoInstance.lineValue) == 0 {
-			importInfoGroups = append(importInfoGroups, importInfoGroup{})
-			currentImportGroupIndex++
+			if len(importInfoGroups[currentImportGroupIndex].importInfos) != 0 {
+				importInfoGroups = append(importInfoGroups, importInfoGroup{})
+				currentImportGroupIndex++
+			}
 
 			// skip line
 			continue
@@ -169,26 +404,55 @@ func (v *verifier) groupImportInfos(importInfos []importInfo, importLineNumbers
 		// if this line doesn't hold a valid import (e.g. comment, comment block) - just ignore it. this helps
 		// us use the parser outputs as the source of whether or not this is an import or a comment
 		if findIntInIntSlice(importLineNumbers, importInfoInstance.lineNum) == -1 {
+
+			// a comment line following imports may open a new group, and comment lines preceding the first
+			// import of a group are its header
+			if v.commentLineNums[importInfoInstance.lineNum] {
+				if v.verifyOptions.CommentGroupBoundaries && len(importInfoGroups[currentImportGroupIndex].importInfos) != 0 {
+					importInfoGroups = append(importInfoGroups, importInfoGroup{})
+					currentImportGroupIndex++
+				}
+
+				if len(importInfoGroups[currentImportGroupIndex].importInfos) == 0 {
+					importInfoGroups[currentImportGroupIndex].hasHeaderComment = true
+				}
+			}
+
+			continue
+		}
+
+		// the parser knows the name of the import and whether it's commented
+		importSpec := v.importSpecsByLine[importInfoInstance.lineNum]
+
+		// import "C" isn't subject to the scheme, and its preamble is no header
+		if importInfoInstance.path == cgoImportPath {
+			if len(importInfoGroups[currentImportGroupIndex].importInfos) == 0 {
+				importInfoGroups[currentImportGroupIndex].hasHeaderComment = false
+			}
+
 			continue
 		}
 
 		// add import info copy
 		importInfoGroups[currentImportGroupIndex].importInfos = append(importInfoGroups[currentImportGroupIndex].importInfos, &importInfo{
-			lineNum:   importInfoInstance.lineNum,
-			lineValue: importInfoInstance.lineValue,
-			path:      importInfoInstance.path,
+			lineNum:            importInfoInstance.lineNum,
+			lineValue:          importInfoInstance.lineValue,
+			path:               importInfoInstance.path,
+			name:               getImportSpecName(importSpec),
+			hasComment:         importSpec.Doc != nil || importSpec.Comment != nil || v.declDocImportSpecs[importSpec],
+			hasTrailingComment: importSpec.Comment != nil,
 		})
 	}
 
-	return v.filterImportC(importInfoGroups)
+	return v.filterEmptyImportGroups(importInfoGroups)
 }
 
-// filter out single `import "C"` from groups since it needs to be on it's own line
-func (v *verifier) filterImportC(importInfoGroups []importInfoGroup) []importInfoGroup {
+// filter out groups holding no imports (e.g. the group `import "C"` was skipped from)
+func (v *verifier) filterEmptyImportGroups(importInfoGroups []importInfoGroup) []importInfoGroup {
 	var filteredGroups []importInfoGroup
 
 	for _, importInfoGroup := range importInfoGroups {
-		if len(importInfoGroup.importInfos) == 1 && importInfoGroup.importInfos[0].path == "C" {
+		if len(importInfoGroup.importInfos) == 0 {
 			continue
 		}
 		filteredGroups = append(filteredGroups, importInfoGroup)
@@ -200,15 +464,47 @@ func (v *verifier) filterImportC(importInfoGroups []importInfoGroup) []importInf
 func (v *verifier) getImportPos(sourceFileReader io.ReadSeeker) ([]int, error) {
 	sourceFileSet := token.NewFileSet()
 
-	sourceNode, err := parser.ParseFile(sourceFileSet, "", sourceFileReader, parser.ImportsOnly)
-	if err != nil {
+	sourceNode, err := parser.ParseFile(sourceFileSet, "", sourceFileReader, parser.ImportsOnly|parser.ParseComments)
+	v.parseViolation = getParseViolation(err)
+
+	// the partial result holds all the imports if the error follows them
+	if err != nil && !isImportDeclsParsed(sourceFileSet, sourceNode, err) {
 		return nil, err
 	}
 
 	var importLineNumbers []int
+	v.importSpecsByLine = map[int]*ast.ImportSpec{}
+	v.commentLineNums = map[int]bool{}
 
 	for _, importSpec := range sourceNode.Imports {
-		importLineNumbers = append(importLineNumbers, sourceFileSet.Position(importSpec.Pos()).Line)
+		importLineNumber := sourceFileSet.Position(importSpec.Pos()).Line
+
+		importLineNumbers = append(importLineNumbers, importLineNumber)
+		v.importSpecsByLine[importLineNumber] = importSpec
+	}
+
+	// a comment preceding an import declaration of a single import (e.g. `import _ "foo"`) documents the import
+	v.declDocImportSpecs = map[*ast.ImportSpec]bool{}
+
+	for _, decl := range sourceNode.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT && genDecl.Doc != nil && len(genDecl.Specs) == 1 {
+			v.declDocImportSpecs[genDecl.Specs[0].(*ast.ImportSpec)] = true
+		}
+	}
+
+	v.cgoImportViolations = getCgoImportViolations(sourceFileSet, sourceNode)
+	v.packageName = sourceNode.Name.Name
+
+	// lines holding only comments
+	for _, commentGroup := range sourceNode.Comments {
+		startLineNum := sourceFileSet.Position(commentGroup.Pos()).Line
+		endLineNum := sourceFileSet.Position(commentGroup.End()).Line
+
+		for lineNum := startLineNum; lineNum <= endLineNum; lineNum++ {
+			if _, found := v.importSpecsByLine[lineNum]; !found {
+				v.commentLineNums[lineNum] = true
+			}
+		}
 	}
 
 	return importLineNumbers, nil
@@ -275,26 +571,40 @@ func findIntInIntSlice(slice []int, value int) int {
 func (v *verifier) verifyImportInfoGroupsOrder(importInfoGroups []importInfoGroup) error {
 	var errorString string
 
+	sortOrder := v.verifyOptions.SortOrder
+
 	for importInfoGroupIndex, importInfoGroup := range importInfoGroups {
-		var importPaths []string
+		var importSortKeys []importSortKey
 
-		// create slice of strings so we can compare
+		// create slice of sort keys so we can compare. the package under test is placed apart from the
+		// other imports
 		for _, importInfo := range importInfoGroup.importInfos {
-			importPaths = append(importPaths, importInfo.path)
+			if importInfo.isPackageUnderTest {
+				continue
+			}
+
+			importSortKeys = append(importSortKeys, importSortKey{
+				name: importInfo.name,
+				path: importInfo.path,
+			})
 		}
 
 		// check that group is sorted
-		if !sort.StringsAreSorted(importPaths) {
+		if !sort.SliceIsSorted(importSortKeys, func(first, second int) bool {
+			return sortOrder.less(importSortKeys[first], importSortKeys[second])
+		}) {
 
 			// created a sorted copy for logging
-			sortedImportGroup := make([]string, len(importPaths))
-			copy(sortedImportGroup, importPaths)
-			sort.Sort(sort.StringSlice(sortedImportGroup))
+			sortedImportSortKeys := make([]importSortKey, len(importSortKeys))
+			copy(sortedImportSortKeys, importSortKeys)
+			sort.SliceStable(sortedImportSortKeys, func(first, second int) bool {
+				return sortOrder.less(sortedImportSortKeys[first], sortedImportSortKeys[second])
+			})
 
 			errorString += fmt.Sprintf("\n- Import group %d is not sorted\n-- Got:\n%s\n\n-- Expected:\n%s\n",
 				importInfoGroupIndex,
-				strings.Join(importPaths, "\n"),
-				strings.Join(sortedImportGroup, "\n"))
+				strings.Join(getImportSortKeyPaths(importSortKeys), "\n"),
+				strings.Join(getImportSortKeyPaths(sortedImportSortKeys), "\n"))
 		}
 	}
 
@@ -305,35 +615,56 @@ func (v *verifier) verifyImportInfoGroupsOrder(importInfoGroups []importInfoGrou
 	return nil
 }
 
+func getImportSortKeyPaths(importSortKeys []importSortKey) []string {
+	var importPaths []string
+
+	for _, importSortKey := range importSortKeys {
+		importPaths = append(importPaths, importSortKey.path)
+	}
+
+	return importPaths
+}
+
 func (v *verifier) classifyImportTypes(importInfoGroups []importInfoGroup) {
 	for _, importInfoGroup := range importInfoGroups {
 
 		// create slice of strings so we can compare
 		for _, importInfo := range importInfoGroup.importInfos {
+			importInfo.classifiedType = classifyImportPath(importInfo.path, v.verifyOptions)
+		}
+	}
+}
 
-			// if the value doesn't contain dot, it's a standard import
-			if !strings.Contains(importInfo.path, ".") {
-				importInfo.classifiedType = importTypeStd
-				continue
-			}
+// classifyImportPath returns the type of an import path
+func classifyImportPath(importPath string, verifyOptions *VerifyOptions) importType {
+	classifiedType, _ := classifyImportPathWithReason(importPath, verifyOptions)
 
-			// if there's no prefix specified, it's either standard or local
-			if len(v.verifyOptions.LocalPrefix) == 0 {
-				importInfo.classifiedType = importTypeLocalOrThirdParty
-				continue
-			}
+	return classifiedType
+}
 
-			if strings.HasPrefix(importInfo.path, v.verifyOptions.LocalPrefix) {
-				importInfo.classifiedType = importTypeLocal
-			} else {
-				importInfo.classifiedType = importTypeThirdParty
-			}
-		}
+// classifyImportPathWithReason returns the type of an import path, along with why it's of that type
+func classifyImportPathWithReason(importPath string, verifyOptions *VerifyOptions) (importType, string) {
+
+	// if the value doesn't contain dot, it's a standard import
+	if !strings.Contains(importPath, ".") {
+		return importTypeStd, `the path has no "." so it's a standard library path`
 	}
+
+	// if there's no prefix specified, it's either standard or local
+	if len(verifyOptions.LocalPrefix) == 0 {
+		return importTypeLocalOrThirdParty, `the path has a "." and no local prefix is specified`
+	}
+
+	if strings.HasPrefix(importPath, verifyOptions.LocalPrefix) {
+		return importTypeLocal, fmt.Sprintf("the path starts with the local prefix %s", verifyOptions.LocalPrefix)
+	}
+
+	return importTypeThirdParty, fmt.Sprintf(`the path has a "." and doesn't start with the local prefix %s`,
+		verifyOptions.LocalPrefix)
 }
 
-func (v *verifier) getVerificationScheme() (verificationScheme, error) {
-	switch v.verifyOptions.Scheme {
+func getVerificationScheme(scheme ImportGroupVerificationScheme) (verificationScheme, error) {
+	switch scheme {
 	case ImportGroupVerificationSchemeStdLocalThirdParty:
 		return newStdLocalThirdPartyScheme(), nil
 	case ImportGroupVerificationSchemeStdThirdPartyLocal:
@@ -361,6 +692,11 @@ func (v *verifier) verifyNonMixedGroups(importInfoGroups []importInfoGroup) erro
 }
 
 func (v *verifier) verifyGroupOrder(importInfoGroups []importInfoGroup, allowedImportOrders [][]importType) error {
+	// nothing is left to order if all the imports were filtered out, e.g. if they're all blank
+	if len(importInfoGroups) == 0 {
+		return nil
+	}
+
 	var existingImportOrder []importType
 
 	// use the first import type as indicative of the following. TODO: to support ImportGroupVerificationSchemeStdNonStd
@@ -11,6 +11,7 @@ type VerifierTestSuite struct {
 	suite.Suite
 	verifier *verifier
 	options  VerifyOptions
+	filePath string
 }
 
 type verificationTestCase struct {
@@ -25,10 +26,14 @@ func (s *VerifierTestSuite) SetupTest() {
 
 	s.verifier, err = newVerifier()
 	s.Require().NoError(err)
+
+	if s.filePath == "" {
+		s.filePath = "fixtures.go"
+	}
 }
 
 func (s *VerifierTestSuite) verify(contents string) error {
-	return s.verifier.verify(strings.NewReader(contents), &s.options)
+	return s.verifier.verify(s.filePath, strings.NewReader(contents), &s.options)
 }
 
 func (s *VerifierTestSuite) verifyTestCases(verificationTestCases []verificationTestCase) {